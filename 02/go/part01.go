@@ -0,0 +1,56 @@
+//go:build ignore
+
+// Day 2: 1202 Program Alarm
+// https://adventofcode.com/2019/day/2
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/dcoxall/advent-of-code-2019/intcode"
+	"github.com/dcoxall/advent-of-code-2019/intcode/trace"
+)
+
+var tracing = trace.Flags()
+
+func main() {
+	flag.Parse()
+	defer tracing.Begin()()
+
+	f, _ := os.Open("./inputs/02.txt")
+	defer f.Close()
+
+	rdr := bufio.NewReader(f)
+	scanner := bufio.NewScanner(rdr)
+	memory := make([]int64, 0)
+
+	for scanner.Scan() {
+		for _, num := range strings.Split(scanner.Text(), ",") {
+			value, err := strconv.ParseInt(num, 10, 64)
+			if err != nil {
+				fmt.Println(err)
+			}
+			memory = append(memory, value)
+		}
+	}
+
+	// restore the gravity assist program to the "1202 program alarm" state
+	memory[1] = 12
+	memory[2] = 2
+
+	prog := intcode.New(memory)
+	tracing.Attach(prog)
+	if _, _, err := prog.Run(context.Background()); err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	fmt.Println(prog.Memory().Read(0))
+}
@@ -0,0 +1,124 @@
+//go:build ignore
+
+// Day 2: 1202 Program Alarm
+// https://adventofcode.com/2019/day/2#part2
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/dcoxall/advent-of-code-2019/intcode"
+	"github.com/dcoxall/advent-of-code-2019/intcode/trace"
+)
+
+var tracing = trace.Flags()
+
+const target = 19690720
+
+type candidate struct {
+	noun int64
+	verb int64
+}
+
+// attempt runs the program with the noun and verb patched in. Each machine
+// loads the program into its own memory so attempts can run side by side.
+func attempt(ctx context.Context, memory []int64, c candidate) (int64, error) {
+	prog := intcode.New(memory)
+	tracing.Attach(prog)
+	prog.Memory().Write(1, c.noun)
+	prog.Memory().Write(2, c.verb)
+
+	if _, _, err := prog.Run(ctx); err != nil {
+		return 0, err
+	}
+	return prog.Memory().Read(0), nil
+}
+
+// search tries every noun and verb across a goroutine per CPU, stopping as
+// soon as one of them finds the target output. Any attempts still running at
+// that point are cancelled.
+func search(ctx context.Context, memory []int64) (candidate, bool) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	candidates := make(chan candidate)
+	results := make(chan candidate, 1)
+	var once sync.Once
+
+	go func() {
+		defer close(candidates)
+		for noun := int64(0); noun < 100; noun++ {
+			for verb := int64(0); verb < 100; verb++ {
+				select {
+				case candidates <- candidate{noun, verb}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < runtime.GOMAXPROCS(0); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for c := range candidates {
+				// some combinations make the program crash, which just
+				// means they aren't the answer
+				if output, err := attempt(ctx, memory, c); err == nil && output == target {
+					once.Do(func() {
+						results <- c
+						cancel()
+					})
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	select {
+	case c := <-results:
+		return c, true
+	default:
+		return candidate{}, false
+	}
+}
+
+func main() {
+	flag.Parse()
+	defer tracing.Begin()()
+
+	f, _ := os.Open("./inputs/02.txt")
+	defer f.Close()
+
+	rdr := bufio.NewReader(f)
+	scanner := bufio.NewScanner(rdr)
+	memory := make([]int64, 0)
+
+	for scanner.Scan() {
+		for _, num := range strings.Split(scanner.Text(), ",") {
+			value, err := strconv.ParseInt(num, 10, 64)
+			if err != nil {
+				fmt.Println(err)
+			}
+			memory = append(memory, value)
+		}
+	}
+
+	if c, ok := search(context.Background(), memory); ok {
+		fmt.Println(100*c.noun + c.verb)
+	} else {
+		fmt.Println("no noun and verb produce", target)
+	}
+}
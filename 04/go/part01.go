@@ -1,3 +1,5 @@
+//go:build ignore
+
 // Day 4: Secure Container
 // https://adventofcode.com/2019/day/4
 
@@ -1,3 +1,5 @@
+//go:build ignore
+
 // Day 6: Universal Orbit Map
 // https://adventofcode.com/2019/day/6#part2
 
@@ -0,0 +1,243 @@
+// Package amplifier runs the amplifier circuits of Day 7 and searches for
+// the phase settings that produce the strongest signal.
+package amplifier
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync"
+
+	"github.com/dcoxall/advent-of-code-2019/intcode"
+	"github.com/dcoxall/advent-of-code-2019/intcode/network"
+)
+
+// Mode is how the amplifiers are wired together.
+type Mode int
+
+const (
+	// Chain passes the signal through each amplifier once.
+	Chain Mode = iota
+	// Loop feeds the last amplifier back into the first until they halt.
+	Loop
+)
+
+// amplifier names in the order they are wired together
+var names = []string{"A", "B", "C", "D", "E", "F", "G", "H", "I", "J"}
+
+const thrusters = "thrusters"
+
+// Circuit is a series of amplifiers all running the same program.
+type Circuit struct {
+	Program []int64
+	Mode    Mode
+	// Attach, if set, is called with every machine before it is run. This
+	// is mostly useful for tracing.
+	Attach func(*intcode.Intcode)
+}
+
+// Result is the best signal found along with the phases that produced it.
+type Result struct {
+	Phases []int64
+	Signal int64
+}
+
+// Signal runs one amplifier per phase setting and returns the signal that
+// reaches the thrusters.
+func (c Circuit) Signal(ctx context.Context, phases []int64) (int64, error) {
+	if len(phases) == 0 {
+		return 0, fmt.Errorf("amplifier: no phases to set")
+	}
+	if len(phases) > len(names) {
+		return 0, fmt.Errorf("amplifier: at most %d amplifiers are supported", len(names))
+	}
+
+	amps := network.New()
+	for i, phase := range phases {
+		seeds := []int64{phase}
+		if i == 0 {
+			// the first amp also needs the initial signal
+			seeds = append(seeds, 0)
+		}
+		if err := amps.AddNode(names[i], c.Program, seeds...); err != nil {
+			return 0, err
+		}
+		if c.Attach != nil {
+			c.Attach(amps.Machine(names[i]))
+		}
+	}
+
+	last := names[len(phases)-1]
+	for i := 0; i < len(phases)-1; i++ {
+		if err := amps.Connect(names[i], names[i+1]); err != nil {
+			return 0, err
+		}
+	}
+
+	// whichever way they are wired the output of the last amp goes to the
+	// thrusters, in a loop it also goes back to the first
+	if err := amps.AddSink(thrusters); err != nil {
+		return 0, err
+	}
+	if err := amps.Connect(last, thrusters); err != nil {
+		return 0, err
+	}
+	if c.Mode == Loop {
+		if err := amps.Connect(last, names[0]); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := amps.Run(ctx); err != nil {
+		return 0, err
+	}
+
+	signal, ok := amps.Last(last, thrusters)
+	if !ok {
+		return 0, fmt.Errorf("amplifier: no signal reached the thrusters with phases %v", phases)
+	}
+	return signal, nil
+}
+
+// better reports whether a is a better result than b. Ties go to whichever
+// permutation comes first so the parallel search finds the same result as
+// the sequential one.
+func better(a, b job, aSignal, bSignal int64) bool {
+	if aSignal != bSignal {
+		return aSignal > bSignal
+	}
+	return a.index < b.index
+}
+
+type job struct {
+	index  int
+	phases []int64
+}
+
+type outcome struct {
+	job
+	signal int64
+	err    error
+}
+
+// SearchSequential tries every permutation of the phases one after another.
+func (c Circuit) SearchSequential(ctx context.Context, phases []int64) (Result, error) {
+	var best outcome
+	found := false
+
+	for i, perm := range Permutations(phases) {
+		signal, err := c.Signal(ctx, perm)
+		if err != nil {
+			return Result{}, err
+		}
+		candidate := outcome{job: job{i, perm}, signal: signal}
+		if !found || better(candidate.job, best.job, candidate.signal, best.signal) {
+			best = candidate
+			found = true
+		}
+	}
+
+	return Result{Phases: best.phases, Signal: best.signal}, nil
+}
+
+// Search tries every permutation of the phases using a pool of workers, or
+// one per CPU if workers isn't positive. It stops at the first error, which
+// also cancels any circuits still running.
+func (c Circuit) Search(ctx context.Context, phases []int64, workers int) (Result, error) {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	jobs := make(chan job)
+	outcomes := make(chan outcome)
+	stop := make(chan struct{})
+
+	go func() {
+		defer close(jobs)
+		for i, perm := range Permutations(phases) {
+			select {
+			case jobs <- job{i, perm}:
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				signal, err := c.Signal(ctx, j.phases)
+				outcomes <- outcome{job: j, signal: signal, err: err}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(outcomes)
+	}()
+
+	var (
+		best  outcome
+		found bool
+		err   error
+	)
+	for o := range outcomes {
+		if o.err != nil {
+			if err == nil {
+				err = o.err
+				close(stop)
+				cancel()
+			}
+			continue
+		}
+		if !found || better(o.job, best.job, o.signal, best.signal) {
+			best = o
+			found = true
+		}
+	}
+
+	if err != nil {
+		return Result{}, err
+	}
+	return Result{Phases: best.phases, Signal: best.signal}, nil
+}
+
+// Permutations returns every ordering of the phases.
+func Permutations(phases []int64) [][]int64 {
+	perms := make([][]int64, 0)
+	if len(phases) == 0 {
+		return perms
+	}
+	for p := make([]int, len(phases)); p[0] < len(p); nextPerm(p) {
+		perms = append(perms, getPerm(phases, p))
+	}
+	return perms
+}
+
+// The following two functions are taken from
+// https://stackoverflow.com/questions/30226438/generate-all-permutations-in-go#30230552
+func nextPerm(p []int) {
+	for i := len(p) - 1; i >= 0; i-- {
+		if i == 0 || p[i] < len(p)-i-1 {
+			p[i]++
+			return
+		}
+		p[i] = 0
+	}
+}
+
+func getPerm(orig []int64, p []int) []int64 {
+	result := make([]int64, len(orig))
+	copy(result, orig)
+	for i, v := range p {
+		result[i], result[i+v] = result[i+v], result[i]
+	}
+	return result
+}
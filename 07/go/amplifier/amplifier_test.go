@@ -0,0 +1,157 @@
+package amplifier
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/dcoxall/advent-of-code-2019/intcode"
+	"github.com/dcoxall/advent-of-code-2019/intcode/network"
+)
+
+func TestSearch(t *testing.T) {
+	examples := []struct {
+		mode    Mode
+		program []int64
+		phases  []int64
+		signal  int64
+	}{
+		{
+			Chain,
+			[]int64{3, 15, 3, 16, 1002, 16, 10, 16, 1, 16, 15, 15, 4, 15, 99, 0, 0},
+			[]int64{4, 3, 2, 1, 0},
+			43210,
+		},
+		{
+			Loop,
+			[]int64{3, 26, 1001, 26, -4, 26, 3, 27, 1002, 27, 2, 27, 1, 27, 26, 27, 4, 27, 1001, 28, -1, 28, 1005, 28, 6, 99, 0, 0, 5},
+			[]int64{9, 8, 7, 6, 5},
+			139629729,
+		},
+	}
+
+	for _, example := range examples {
+		circuit := Circuit{Program: example.program, Mode: example.mode}
+		phases := []int64{0, 1, 2, 3, 4}
+		if example.mode == Loop {
+			phases = []int64{5, 6, 7, 8, 9}
+		}
+
+		sequential, err := circuit.SearchSequential(context.Background(), phases)
+		if err != nil {
+			t.Fatal(err)
+		}
+		parallel, err := circuit.Search(context.Background(), phases, 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, result := range []Result{sequential, parallel} {
+			if result.Signal != example.signal || !reflect.DeepEqual(result.Phases, example.phases) {
+				t.Errorf("expected %d from %v but got %d from %v", example.signal, example.phases, result.Signal, result.Phases)
+			}
+		}
+	}
+}
+
+func TestSignalErrors(t *testing.T) {
+	circuit := Circuit{Program: []int64{3, 0, 3, 0, 4, 0, 99}}
+	phases := map[string][]int64{
+		"amplifier: no phases to set":                    nil,
+		"amplifier: at most 10 amplifiers are supported": make([]int64, 11),
+	}
+	for msg, p := range phases {
+		if _, err := circuit.Signal(context.Background(), p); err == nil || err.Error() != msg {
+			t.Errorf("%v: expected %q but got %v", p, msg, err)
+		}
+	}
+}
+
+// original is how the solutions searched before this package, trying each
+// permutation in turn. A chain ran each amplifier in its own goroutine
+// connected by channels and a loop ran them as a network.
+func original(program []int64, mode Mode, phases []int64) (int64, error) {
+	var highest int64
+	for _, perm := range Permutations(phases) {
+		var signal int64
+		if mode == Chain {
+			input := make(chan int64, 5)
+			entry := input
+			for _, phase := range perm {
+				output := make(chan int64, 5)
+				input <- phase
+				prog := intcode.NewWithIO(program, intcode.ChannelInput(input), intcode.ChannelOutput(output))
+				go prog.Run(context.Background())
+				input = output
+			}
+			entry <- 0
+			signal = <-input
+		} else {
+			amps := network.New()
+			for i, phase := range perm {
+				seeds := []int64{phase}
+				if i == 0 {
+					seeds = append(seeds, 0)
+				}
+				amps.AddNode(names[i], program, seeds...)
+			}
+			for i := range perm {
+				amps.Connect(names[i], names[(i+1)%len(perm)])
+			}
+			if err := amps.Run(context.Background()); err != nil {
+				return 0, err
+			}
+			signal, _ = amps.Last(names[len(perm)-1], names[0])
+		}
+		highest = max(highest, signal)
+	}
+	return highest, nil
+}
+
+func BenchmarkSearch(b *testing.B) {
+	f, err := os.Open("../../../inputs/07.txt")
+	if err != nil {
+		b.Fatal(err)
+	}
+	program, err := intcode.Parse(f)
+	f.Close()
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	modes := []struct {
+		name   string
+		mode   Mode
+		phases []int64
+	}{
+		{"Chain", Chain, []int64{0, 1, 2, 3, 4}},
+		{"Loop", Loop, []int64{5, 6, 7, 8, 9}},
+	}
+
+	for _, m := range modes {
+		circuit := Circuit{Program: program, Mode: m.mode}
+		want, err := circuit.Search(context.Background(), m.phases, 0)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if got, err := original(program, m.mode, m.phases); err != nil || got != want.Signal {
+			b.Fatalf("expected the original search to find %d but got %d, %v", want.Signal, got, err)
+		}
+
+		b.Run(m.name+"/Original", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := original(program, m.mode, m.phases); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+		b.Run(m.name+"/Parallel", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := circuit.Search(context.Background(), m.phases, 0); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+// Package hull simulates the emergency hull painting robot of Day 11.
+//
+// The robot sits on a grid of black and white panels. Each step its brain is
+// told the colour of the panel underneath it and decides which colour to
+// paint it and which way to turn, after which the robot moves forward one
+// panel.
+package hull
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dcoxall/advent-of-code-2019/grid"
+	"github.com/dcoxall/advent-of-code-2019/intcode"
+)
+
+const (
+	Black int64 = 0
+	White int64 = 1
+)
+
+const (
+	TurnLeft  int64 = 0
+	TurnRight int64 = 1
+)
+
+// Brain decides what the robot does next. Given the colour of the panel the
+// robot is on it returns the colour to paint and the direction to turn, or
+// false when it has nothing more to do.
+type Brain interface {
+	Next(ctx context.Context, colour int64) (paint int64, turn int64, ok bool, err error)
+}
+
+// IntcodeBrain runs the robot's Intcode program.
+type IntcodeBrain struct {
+	prog *intcode.Intcode
+}
+
+func NewIntcodeBrain(program []int64) *IntcodeBrain {
+	return &IntcodeBrain{prog: intcode.New(program)}
+}
+
+// Machine returns the machine running the program, so that it can be traced.
+func (b *IntcodeBrain) Machine() *intcode.Intcode {
+	return b.prog
+}
+
+func (b *IntcodeBrain) Next(ctx context.Context, colour int64) (int64, int64, bool, error) {
+	if b.prog.Halted() {
+		return 0, 0, false, nil
+	}
+
+	b.prog.Input().(*intcode.Queue).Push(colour)
+	if _, _, err := b.prog.Run(ctx); err != nil {
+		return 0, 0, false, err
+	}
+
+	okPaint, paint := b.prog.ReadOutput()
+	okTurn, turn := b.prog.ReadOutput()
+	if !okPaint && b.prog.Halted() {
+		return 0, 0, false, nil
+	}
+	if !okPaint || !okTurn {
+		return 0, 0, false, fmt.Errorf("hull: expected a colour and a turn from the brain at address %d", b.prog.Address())
+	}
+	return paint, turn, true, nil
+}
+
+// HullRobot paints panels as told by its brain.
+type HullRobot struct {
+	brain    Brain
+	panels   map[grid.Point]int64
+	painted  map[grid.Point]bool
+	position grid.Point
+	heading  grid.Direction
+	onStep   func(r *HullRobot)
+}
+
+// New creates a robot at the origin facing up, with every panel black.
+func New(brain Brain) *HullRobot {
+	return &HullRobot{
+		brain:   brain,
+		panels:  make(map[grid.Point]int64),
+		painted: make(map[grid.Point]bool),
+		heading: grid.Up,
+	}
+}
+
+// SetPanel sets the colour of a panel without counting it as painted, such
+// as for the panel the robot starts on.
+func (r *HullRobot) SetPanel(p grid.Point, colour int64) {
+	r.panels[p] = colour
+}
+
+// OnStep calls fn after every step the robot takes.
+func (r *HullRobot) OnStep(fn func(r *HullRobot)) {
+	r.onStep = fn
+}
+
+// Step paints the current panel, turns and moves forward. It returns false
+// once the brain has finished.
+func (r *HullRobot) Step(ctx context.Context) (bool, error) {
+	paint, turn, ok, err := r.brain.Next(ctx, r.panels[r.position])
+	if err != nil || !ok {
+		return false, err
+	}
+
+	r.panels[r.position] = paint
+	r.painted[r.position] = true
+	if turn == TurnLeft {
+		r.heading = r.heading.TurnLeft()
+	} else {
+		r.heading = r.heading.TurnRight()
+	}
+	r.position = r.position.Move(r.heading)
+
+	if r.onStep != nil {
+		r.onStep(r)
+	}
+	return true, nil
+}
+
+// Run keeps stepping until the brain has finished.
+func (r *HullRobot) Run(ctx context.Context) error {
+	for {
+		ok, err := r.Step(ctx)
+		if err != nil || !ok {
+			return err
+		}
+	}
+}
+
+// Panels returns the colour of every panel that has been set or painted. Any
+// other panel is black.
+func (r *HullRobot) Panels() map[grid.Point]int64 {
+	return r.panels
+}
+
+// Painted returns how many panels have been painted at least once.
+func (r *HullRobot) Painted() int {
+	return len(r.painted)
+}
+
+func (r *HullRobot) Position() grid.Point {
+	return r.position
+}
+
+func (r *HullRobot) Heading() grid.Direction {
+	return r.heading
+}
@@ -0,0 +1,91 @@
+package hull
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/dcoxall/advent-of-code-2019/grid"
+)
+
+// scripted is a brain that gives a fixed series of instructions and records
+// the colours it was shown.
+type scripted struct {
+	moves [][2]int64
+	seen  []int64
+}
+
+func (s *scripted) Next(ctx context.Context, colour int64) (int64, int64, bool, error) {
+	if len(s.moves) == 0 {
+		return 0, 0, false, nil
+	}
+	s.seen = append(s.seen, colour)
+	move := s.moves[0]
+	s.moves = s.moves[1:]
+	return move[0], move[1], true, nil
+}
+
+func TestHullRobot(t *testing.T) {
+	// the example from the puzzle
+	brain := &scripted{moves: [][2]int64{
+		{White, TurnLeft},
+		{Black, TurnLeft},
+		{White, TurnLeft},
+		{White, TurnLeft},
+		{Black, TurnRight},
+		{White, TurnLeft},
+		{White, TurnLeft},
+	}}
+	robot := New(brain)
+
+	steps := 0
+	robot.OnStep(func(r *HullRobot) { steps++ })
+
+	if err := robot.Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if steps != 7 {
+		t.Errorf("expected 7 steps but got %d", steps)
+	}
+	if robot.Painted() != 6 {
+		t.Errorf("expected 6 panels painted but got %d", robot.Painted())
+	}
+	if expected := []int64{Black, Black, Black, Black, White, Black, Black}; !reflect.DeepEqual(brain.seen, expected) {
+		t.Errorf("expected the brain to see %v but got %v", expected, brain.seen)
+	}
+	if pos := robot.Position(); pos != (grid.Point{X: 0, Y: -1}) {
+		t.Errorf("expected to finish at {0 -1} but got %v", pos)
+	}
+	if robot.Heading() != grid.Left {
+		t.Errorf("expected to finish facing Left but got %s", robot.Heading())
+	}
+
+	expected := map[grid.Point]int64{
+		{X: 0, Y: 0}:  Black,
+		{X: -1, Y: 0}: Black,
+		{X: -1, Y: 1}: White,
+		{X: 0, Y: 1}:  White,
+		{X: 1, Y: 0}:  White,
+		{X: 1, Y: -1}: White,
+	}
+	if !reflect.DeepEqual(robot.Panels(), expected) {
+		t.Errorf("expected panels %v but got %v", expected, robot.Panels())
+	}
+}
+
+func TestStartingPanel(t *testing.T) {
+	brain := &scripted{moves: [][2]int64{{Black, TurnRight}}}
+	robot := New(brain)
+	robot.SetPanel(grid.Point{}, White)
+
+	if err := robot.Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(brain.seen, []int64{White}) {
+		t.Errorf("expected the brain to see the white starting panel but got %v", brain.seen)
+	}
+	if robot.Painted() != 1 || robot.Heading() != grid.Right || robot.Position() != (grid.Point{X: 1, Y: 0}) {
+		t.Errorf("unexpected robot after one step: painted %d, at %v facing %s", robot.Painted(), robot.Position(), robot.Heading())
+	}
+}
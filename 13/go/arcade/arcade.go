@@ -0,0 +1,207 @@
+// Package arcade emulates the arcade cabinet of Day 13, keeping track of the
+// screen and the score as the game draws them.
+//
+// The game draws by outputting an x, y and tile id for each change to the
+// screen, apart from the score which it sets with an x of -1 and a y of 0.
+// Whenever it needs to know where the joystick is it asks for an input of
+// -1, 0 or 1 for left, neutral and right.
+package arcade
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+
+	"github.com/dcoxall/advent-of-code-2019/grid"
+	"github.com/dcoxall/advent-of-code-2019/intcode"
+)
+
+// Tile ids
+const (
+	Empty int64 = iota
+	Wall
+	Block
+	Paddle
+	Ball
+)
+
+// Joystick positions
+const (
+	Left    int64 = -1
+	Neutral int64 = 0
+	Right   int64 = 1
+)
+
+// scoreX marks an output as the score rather than a tile
+const scoreX = -1
+
+type Arcade struct {
+	prog     *intcode.Intcode
+	screen   map[grid.Point]int64
+	score    int64
+	ball     grid.Point
+	paddle   grid.Point
+	pending  []int64
+	joystick []int64
+}
+
+// New loads the game into a cabinet ready to be played.
+func New(program []int64) *Arcade {
+	return &Arcade{
+		prog:   intcode.New(program),
+		screen: make(map[grid.Point]int64),
+	}
+}
+
+// FreePlay sets the game to not need any quarters. It has to be done before
+// the game starts.
+func (a *Arcade) FreePlay() {
+	a.prog.Memory().Write(0, 2)
+}
+
+// Machine returns the machine running the game, so that it can be traced.
+func (a *Arcade) Machine() *intcode.Intcode {
+	return a.prog
+}
+
+// Run runs the game until it needs the joystick, which returns true, or until
+// it is over.
+func (a *Arcade) Run(ctx context.Context) (bool, error) {
+	state, _, err := a.prog.Run(ctx)
+	a.draw()
+	if err != nil {
+		return false, err
+	}
+	return state == intcode.NeedsInput, nil
+}
+
+// Move sets the joystick and carries on running the game. Every move is kept
+// so that the game can be replayed.
+func (a *Arcade) Move(ctx context.Context, joystick int64) (bool, error) {
+	a.joystick = append(a.joystick, joystick)
+	a.prog.Input().(*intcode.Queue).Push(joystick)
+	return a.Run(ctx)
+}
+
+// draw applies everything the game has output to the screen.
+func (a *Arcade) draw() {
+	for ok, val := a.prog.ReadOutput(); ok; ok, val = a.prog.ReadOutput() {
+		if a.pending = append(a.pending, val); len(a.pending) < 3 {
+			continue
+		}
+
+		point := grid.Point{X: int(a.pending[0]), Y: int(a.pending[1])}
+		tile := a.pending[2]
+		a.pending = a.pending[:0]
+
+		if point.X == scoreX {
+			a.score = tile
+			continue
+		}
+		switch tile {
+		case Ball:
+			a.ball = point
+		case Paddle:
+			a.paddle = point
+		}
+		a.screen[point] = tile
+	}
+}
+
+// Play runs the game until it is over, asking the strategy for every move.
+func (a *Arcade) Play(ctx context.Context, strategy JoystickStrategy) error {
+	waiting, err := a.Run(ctx)
+	for waiting && err == nil {
+		var joystick int64
+		if joystick, err = strategy.Joystick(a); err != nil {
+			return err
+		}
+		waiting, err = a.Move(ctx, joystick)
+	}
+	return err
+}
+
+// Replay makes the same moves as a game that was recorded.
+func Replay(joystick []int64) JoystickStrategy {
+	moves := 0
+	return Controller(func(a *Arcade) (int64, error) {
+		if moves >= len(joystick) {
+			return 0, fmt.Errorf("arcade: the replay ran out after %d moves", moves)
+		}
+		moves++
+		return joystick[moves-1], nil
+	})
+}
+
+// WriteJoystick saves a record of joystick moves.
+func WriteJoystick(w io.Writer, joystick []int64) error {
+	return intcode.Format(w, joystick)
+}
+
+// ReadJoystick loads a record of joystick moves.
+func ReadJoystick(r io.Reader) ([]int64, error) {
+	return intcode.Parse(r)
+}
+
+// Screen returns every tile drawn so far.
+func (a *Arcade) Screen() map[grid.Point]int64 {
+	return a.screen
+}
+
+func (a *Arcade) Score() int64 {
+	return a.score
+}
+
+func (a *Arcade) Ball() grid.Point {
+	return a.ball
+}
+
+func (a *Arcade) Paddle() grid.Point {
+	return a.paddle
+}
+
+// Count returns how many of a tile are on the screen.
+func (a *Arcade) Count(tile int64) int {
+	count := 0
+	for _, t := range a.screen {
+		if t == tile {
+			count++
+		}
+	}
+	return count
+}
+
+// Blocks returns how many blocks are left on the screen.
+func (a *Arcade) Blocks() int {
+	return a.Count(Block)
+}
+
+// Joystick returns every move made so far.
+func (a *Arcade) Joystick() []int64 {
+	return a.joystick
+}
+
+// Frames returns how many times the game has asked for the joystick.
+func (a *Arcade) Frames() int {
+	return len(a.joystick)
+}
+
+// Clone creates a separate cabinet that carries on from the same point in the
+// game, so that moves can be tried out without affecting this one. The clone
+// isn't traced.
+func (a *Arcade) Clone() *Arcade {
+	clone := *a
+	clone.prog = a.prog.Clone()
+	clone.prog.SetTracer(nil)
+	clone.screen = maps.Clone(a.screen)
+	clone.pending = slices.Clone(a.pending)
+	clone.joystick = slices.Clone(a.joystick)
+	return &clone
+}
+
+// Over reports whether the game has finished.
+func (a *Arcade) Over() bool {
+	return a.prog.Halted()
+}
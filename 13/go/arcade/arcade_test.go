@@ -0,0 +1,173 @@
+package arcade
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/dcoxall/advent-of-code-2019/grid"
+	"github.com/dcoxall/advent-of-code-2019/intcode/asm"
+)
+
+// game draws a tiny screen, then sets the score to 10 more than the first
+// move of the joystick and halts after the second.
+const game = `
+        OUT #0      ; a wall at 0,0
+        OUT #0
+        OUT #1
+        OUT #2      ; a block at 2,1
+        OUT #1
+        OUT #2
+        OUT #3      ; the paddle at 3,2
+        OUT #2
+        OUT #3
+        OUT #1      ; the ball at 1,1
+        OUT #1
+        OUT #4
+        IN  [joy]
+        ADD [joy], #10, [score]
+        OUT #-1
+        OUT #0
+        OUT [score]
+        IN  [joy]
+        HLT
+joy:    db 0
+score:  db 0
+`
+
+// screen draws a row of 10 blocks with a loop and then a few more tiles,
+// including one that replaces a block.
+const screen = `
+loop:   OUT [x]     ; a block at x,0
+        OUT #0
+        OUT #2
+        ADD [x], #1, [x]
+        LT  [x], #10, [more]
+        JT  [more], #loop
+        OUT #0      ; walls at 0,1 and 1,1
+        OUT #1
+        OUT #1
+        OUT #1
+        OUT #1
+        OUT #1
+        OUT #4      ; the paddle at 4,2
+        OUT #2
+        OUT #3
+        OUT #5      ; the ball at 5,1
+        OUT #1
+        OUT #4
+        OUT #3      ; the block at 3,0 is broken
+        OUT #0
+        OUT #0
+        OUT #-1     ; a score of 12
+        OUT #0
+        OUT #12
+        HLT
+x:      db 0
+more:   db 0
+`
+
+func load(t *testing.T, source string) []int64 {
+	program, err := asm.Assemble(strings.NewReader(source))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return program
+}
+
+func TestScreen(t *testing.T) {
+	a := New(load(t, screen))
+	if waiting, err := a.Run(context.Background()); err != nil || waiting {
+		t.Fatalf("expected the game to finish but got %v, %v", waiting, err)
+	}
+
+	counts := map[int64]int{Empty: 1, Wall: 2, Block: 9, Paddle: 1, Ball: 1}
+	for tile, expected := range counts {
+		if count := a.Count(tile); count != expected {
+			t.Errorf("tile %d: expected %d but got %d", tile, expected, count)
+		}
+	}
+	if len(a.Screen()) != 14 {
+		t.Errorf("expected 14 tiles but got %d", len(a.Screen()))
+	}
+	if a.Blocks() != 9 || a.Score() != 12 || !a.Over() || a.Frames() != 0 {
+		t.Errorf("unexpected blocks %d, score %d, over %v or frames %d", a.Blocks(), a.Score(), a.Over(), a.Frames())
+	}
+}
+
+func TestPlay(t *testing.T) {
+	a := New(load(t, game))
+
+	waiting, err := a.Run(context.Background())
+	if err != nil || !waiting {
+		t.Fatalf("expected the game to wait for the joystick but got %v, %v", waiting, err)
+	}
+
+	expected := map[grid.Point]int64{
+		{X: 0, Y: 0}: Wall,
+		{X: 2, Y: 1}: Block,
+		{X: 3, Y: 2}: Paddle,
+		{X: 1, Y: 1}: Ball,
+	}
+	if !reflect.DeepEqual(a.Screen(), expected) {
+		t.Errorf("expected the screen %v but got %v", expected, a.Screen())
+	}
+	if a.Ball() != (grid.Point{X: 1, Y: 1}) || a.Paddle() != (grid.Point{X: 3, Y: 2}) || a.Blocks() != 1 {
+		t.Errorf("unexpected ball %v, paddle %v or blocks %d", a.Ball(), a.Paddle(), a.Blocks())
+	}
+
+	// the ball is to the left of the paddle
+	if err := a.Play(context.Background(), Tracker); err != nil {
+		t.Fatal(err)
+	}
+	if !a.Over() {
+		t.Error("expected the game to be over")
+	}
+	if a.Score() != 9 {
+		t.Errorf("expected a score of 9 but got %d", a.Score())
+	}
+	if !reflect.DeepEqual(a.Joystick(), []int64{Left, Left}) {
+		t.Errorf("expected the joystick to move left twice but got %v", a.Joystick())
+	}
+}
+
+func TestReplay(t *testing.T) {
+	recorded := []int64{Right, Neutral}
+
+	var buf bytes.Buffer
+	if err := WriteJoystick(&buf, recorded); err != nil {
+		t.Fatal(err)
+	}
+	joystick, err := ReadJoystick(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a := New(load(t, game))
+	if err := a.Play(context.Background(), Replay(joystick)); err != nil {
+		t.Fatal(err)
+	}
+	if a.Score() != 11 || !reflect.DeepEqual(a.Joystick(), recorded) {
+		t.Errorf("expected a score of 11 from %v but got %d from %v", recorded, a.Score(), a.Joystick())
+	}
+
+	// a recording that is too short can't finish the game
+	a = New(load(t, game))
+	if err := a.Play(context.Background(), Replay(recorded[:1])); err == nil {
+		t.Error("expected the replay to run out")
+	}
+}
+
+func TestKeyboard(t *testing.T) {
+	keyboard := Keyboard(strings.NewReader("a\n\nD\nx\n"))
+	for _, expected := range []int64{Left, Neutral, Right, Neutral} {
+		if move, err := keyboard.Joystick(nil); err != nil || move != expected {
+			t.Errorf("expected %d but got %d, %v", expected, move, err)
+		}
+	}
+	if _, err := keyboard.Joystick(nil); err == nil {
+		t.Error("expected the keyboard to run out of moves")
+	}
+}
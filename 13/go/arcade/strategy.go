@@ -0,0 +1,130 @@
+package arcade
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dcoxall/advent-of-code-2019/grid"
+)
+
+// JoystickStrategy decides where the joystick should be for the next move.
+type JoystickStrategy interface {
+	Joystick(a *Arcade) (int64, error)
+}
+
+// Controller allows a plain function to be used as a strategy.
+type Controller func(a *Arcade) (int64, error)
+
+func (c Controller) Joystick(a *Arcade) (int64, error) {
+	return c(a)
+}
+
+// StrategyNames lists the built in strategies that can be made by
+// NewStrategy.
+var StrategyNames = []string{"tracker", "predictive", "lookahead"}
+
+// NewStrategy creates one of the built in strategies by name. Each is ready
+// to play a new game.
+func NewStrategy(name string) (JoystickStrategy, error) {
+	switch name {
+	case "tracker":
+		return Tracker, nil
+	case "predictive":
+		return &Predictive{}, nil
+	case "lookahead":
+		return &Lookahead{}, nil
+	}
+	return nil, fmt.Errorf("arcade: unknown strategy %q", name)
+}
+
+// towards moves the paddle one step closer to x.
+func towards(paddle grid.Point, x int) int64 {
+	switch {
+	case x < paddle.X:
+		return Left
+	case x > paddle.X:
+		return Right
+	}
+	return Neutral
+}
+
+// Tracker keeps the paddle under the ball.
+var Tracker JoystickStrategy = Controller(func(a *Arcade) (int64, error) {
+	return towards(a.paddle, a.ball.X), nil
+})
+
+// Predictive works out where the ball is heading from its last two
+// positions and moves the paddle to meet it. It allows for the ball bouncing
+// off walls but not blocks, which it corrects for once the ball has changed
+// course.
+type Predictive struct {
+	prev  grid.Point
+	known bool
+}
+
+func (p *Predictive) Joystick(a *Arcade) (int64, error) {
+	target := a.ball.X
+	if p.known {
+		dx, dy := a.ball.X-p.prev.X, a.ball.Y-p.prev.Y
+		if dy > 0 {
+			target = p.predict(a, dx)
+		}
+	}
+
+	p.prev, p.known = a.ball, true
+	return towards(a.paddle, target), nil
+}
+
+// predict follows the ball down to the row above the paddle.
+func (p *Predictive) predict(a *Arcade, dx int) int {
+	ball := a.ball
+	for ball.Y < a.paddle.Y-1 {
+		next := grid.Point{X: ball.X + dx, Y: ball.Y + 1}
+		if a.screen[grid.Point{X: next.X, Y: ball.Y}] == Wall {
+			dx = -dx
+			next.X = ball.X + dx
+		}
+		ball = next
+	}
+	return ball.X
+}
+
+// Lookahead plays a copy of the game ahead of the real one to see exactly
+// where the ball will be when it reaches the paddle, which takes blocks into
+// account as well. It only looks again once the ball has bounced.
+type Lookahead struct {
+	target int
+	known  bool
+}
+
+func (l *Lookahead) Joystick(a *Arcade) (int64, error) {
+	if a.ball.Y >= a.paddle.Y-1 {
+		// the paddle should already be under the ball, and moving it now
+		// would change how the ball bounces from what the next look ahead
+		// sees
+		l.known = false
+		return towards(a.paddle, a.ball.X), nil
+	}
+
+	if !l.known {
+		target, err := l.lookahead(a)
+		if err != nil {
+			return 0, err
+		}
+		l.target, l.known = target, true
+	}
+	return towards(a.paddle, l.target), nil
+}
+
+// lookahead runs a copy of the game until the ball next reaches the row
+// above the paddle, returning where it will be.
+func (l *Lookahead) lookahead(a *Arcade) (int, error) {
+	future := a.Clone()
+	for waiting := true; waiting && future.ball.Y < future.paddle.Y-1; {
+		var err error
+		if waiting, err = future.Move(context.Background(), Neutral); err != nil {
+			return 0, err
+		}
+	}
+	return future.ball.X, nil
+}
@@ -0,0 +1,67 @@
+package arcade
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/dcoxall/advent-of-code-2019/grid"
+	"github.com/dcoxall/advent-of-code-2019/intcode"
+)
+
+func TestPredictiveBounce(t *testing.T) {
+	// walls at x=0 and x=5 with the paddle on row 6
+	a := New(nil)
+	for y := 0; y < 8; y++ {
+		a.screen[grid.Point{X: 0, Y: y}] = Wall
+		a.screen[grid.Point{X: 5, Y: y}] = Wall
+	}
+	a.paddle = grid.Point{X: 2, Y: 6}
+
+	p := &Predictive{}
+	a.ball = grid.Point{X: 2, Y: 0}
+	p.Joystick(a)
+
+	// heading down and right the ball bounces off the wall at x=5 and
+	// reaches row 5 at x=1
+	a.ball = grid.Point{X: 3, Y: 1}
+	if x := p.predict(a, 1); x != 1 {
+		t.Errorf("expected the ball at x=1 but predicted %d", x)
+	}
+	if move, _ := p.Joystick(a); move != Left {
+		t.Errorf("expected to move left but moved %d", move)
+	}
+
+	// heading up it follows the ball
+	a.ball = grid.Point{X: 4, Y: 0}
+	if move, _ := p.Joystick(a); move != Right {
+		t.Errorf("expected to move right but moved %d", move)
+	}
+}
+
+func TestStrategies(t *testing.T) {
+	f, err := os.Open("../../../inputs/13.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	program, err := intcode.Parse(f)
+	f.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range StrategyNames {
+		strategy, err := NewStrategy(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		a := New(program)
+		a.FreePlay()
+		if err := a.Play(context.Background(), strategy); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !a.Over() || a.Blocks() != 0 {
+			t.Errorf("%s: expected to clear the screen but %d blocks are left", name, a.Blocks())
+		}
+	}
+}
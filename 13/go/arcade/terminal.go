@@ -0,0 +1,88 @@
+package arcade
+
+import (
+	"bufio"
+	"fmt"
+	"image/color"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/dcoxall/advent-of-code-2019/canvas"
+)
+
+// clear moves the cursor to the top left and clears the screen
+const clearScreen = "\033[H\033[2J"
+
+var palette = canvas.Palette(map[int64]rune{
+	Wall:   '█',
+	Block:  '#',
+	Paddle: '=',
+	Ball:   'o',
+}, ' ')
+
+// Colours is how each tile is coloured when the screen is saved as an image.
+var Colours = canvas.Colours(map[int64]color.Color{
+	Wall:   color.Gray{Y: 0x80},
+	Block:  color.RGBA{R: 0xe0, G: 0x80, B: 0x20, A: 0xff},
+	Paddle: color.White,
+	Ball:   color.RGBA{R: 0x40, G: 0xc0, B: 0xff, A: 0xff},
+}, color.Black)
+
+// Picture returns a canvas of the screen. It keeps up with the game so it can
+// be drawn again as the game is played.
+func (a *Arcade) Picture() *canvas.Canvas[int64] {
+	return canvas.New(a.screen, palette)
+}
+
+// Terminal draws the game in a terminal that understands ANSI escape codes.
+type Terminal struct {
+	w io.Writer
+	// Delay is how long to wait after drawing each frame, so the game can
+	// be followed by eye.
+	Delay time.Duration
+}
+
+func NewTerminal(w io.Writer) *Terminal {
+	return &Terminal{w: w}
+}
+
+// Draw replaces whatever is on the terminal with the screen and score.
+func (t *Terminal) Draw(a *Arcade) error {
+	_, err := fmt.Fprintf(t.w, "%s%sScore: %d  Blocks: %d\n", clearScreen, a.Picture(), a.score, a.Blocks())
+	return err
+}
+
+// Watch draws every frame before the strategy decides on a move.
+func (t *Terminal) Watch(strategy JoystickStrategy) JoystickStrategy {
+	return Controller(func(a *Arcade) (int64, error) {
+		if err := t.Draw(a); err != nil {
+			return 0, err
+		}
+		time.Sleep(t.Delay)
+		return strategy.Joystick(a)
+	})
+}
+
+// Keyboard lets someone play the game, one line at a time. A line starting
+// with a or h moves left, d or l moves right and anything else, including
+// just pressing enter, leaves the joystick in the middle.
+func Keyboard(r io.Reader) JoystickStrategy {
+	scanner := bufio.NewScanner(r)
+	return Controller(func(a *Arcade) (int64, error) {
+		if !scanner.Scan() {
+			if err := scanner.Err(); err != nil {
+				return 0, err
+			}
+			return 0, fmt.Errorf("arcade: no more moves from the keyboard")
+		}
+
+		switch line := strings.ToLower(strings.TrimSpace(scanner.Text())); {
+		case strings.HasPrefix(line, "a"), strings.HasPrefix(line, "h"):
+			return Left, nil
+		case strings.HasPrefix(line, "d"), strings.HasPrefix(line, "l"):
+			return Right, nil
+		}
+		return Neutral, nil
+	})
+}
@@ -0,0 +1,57 @@
+//go:build ignore
+
+// Day 13: Care Package
+// Plays the game with each of the joystick strategies and compares them.
+//
+//	$ go run 13/go/harness.go
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/dcoxall/advent-of-code-2019/13/go/arcade"
+	"github.com/dcoxall/advent-of-code-2019/intcode"
+)
+
+func main() {
+	f, err := os.Open("./inputs/13.txt")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	memory, err := intcode.Parse(f)
+	f.Close()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "strategy\tscore\tframes\tblocks left\ttime\t")
+
+	for _, name := range arcade.StrategyNames {
+		strategy, err := arcade.NewStrategy(name)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		game := arcade.New(memory)
+		game.FreePlay()
+
+		start := time.Now()
+		if err := game.Play(context.Background(), strategy); err != nil {
+			fmt.Printf("%s: %v\n", name, err)
+			continue
+		}
+		elapsed := time.Since(start)
+
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%s\t\n", name, game.Score(), game.Frames(), game.Blocks(), elapsed.Round(time.Microsecond))
+	}
+	w.Flush()
+}
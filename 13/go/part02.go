@@ -1,3 +1,5 @@
+//go:build ignore
+
 // Day 13: Care Package
 // https://adventofcode.com/2019/day/13#part2
 
@@ -5,251 +7,57 @@ package main
 
 import (
 	"bufio"
+	"context"
+	"flag"
 	"fmt"
 	"os"
 	"strconv"
 	"strings"
-)
-
-type Intcode struct {
-	memory       map[int64]int64
-	address      int64
-	halted       bool
-	relativeBase int64
-	paused       bool
-	inputs       []int64
-	outputs      []int64
-}
-
-type Instruction struct {
-	Opcode int64
-	Modes  []int64
-}
-
-func NewIntcodeProg(memory []int64) *Intcode {
-	mem := make(map[int64]int64, len(memory))
-
-	for i, n := range memory {
-		mem[int64(i)] = n
-	}
-
-	return &Intcode{
-		memory:  mem,
-		halted:  false,
-		paused:  false,
-		outputs: make([]int64, 0),
-		inputs:  make([]int64, 0),
-	}
-}
-
-func (prog *Intcode) Execute() {
-	for !prog.halted && !prog.paused {
-		instruction := prog.parseInstruction(prog.memory[prog.address])
-		prog.executeInstruction(instruction)
-	}
-}
-
-func (prog *Intcode) ResumeWith(vals ...int64) {
-	prog.inputs = append(prog.inputs, vals...)
-	prog.paused = false
-	prog.Execute()
-}
-
-func (prog *Intcode) ReadOutput() (bool, int64) {
-	if len(prog.outputs) > 0 {
-		val := prog.outputs[0]
-		prog.outputs = prog.outputs[1:]
-		return true, val
-	}
-	return false, 0
-}
-
-func (prog *Intcode) opAdd(a, b, c int64) {
-	prog.memory[c] = prog.memory[a] + prog.memory[b]
-	prog.address += 4
-}
-
-func (prog *Intcode) opMultiply(a, b, c int64) {
-	prog.memory[c] = prog.memory[a] * prog.memory[b]
-	prog.address += 4
-}
-
-func (prog *Intcode) opInput(a int64) {
-	if len(prog.inputs) == 0 {
-		prog.paused = true
-	} else {
-		prog.memory[a] = prog.inputs[0]
-		prog.inputs = prog.inputs[1:]
-		prog.address += 2
-	}
-}
-
-func (prog *Intcode) opOutput(a int64) {
-	prog.outputs = append(prog.outputs, prog.memory[a])
-	prog.address += 2
-}
-
-func (prog *Intcode) opJmpTrue(a, b int64) {
-	if prog.memory[a] != 0 {
-		prog.address = prog.memory[b]
-	} else {
-		prog.address += 3
-	}
-}
-
-func (prog *Intcode) opJmpFalse(a, b int64) {
-	if prog.memory[a] == 0 {
-		prog.address = prog.memory[b]
-	} else {
-		prog.address += 3
-	}
-}
-
-func (prog *Intcode) opLessThan(a, b, c int64) {
-	if prog.memory[a] < prog.memory[b] {
-		prog.memory[c] = 1
-	} else {
-		prog.memory[c] = 0
-	}
-	prog.address += 4
-}
+	"time"
 
-func (prog *Intcode) opEquals(a, b, c int64) {
-	if prog.memory[a] == prog.memory[b] {
-		prog.memory[c] = 1
-	} else {
-		prog.memory[c] = 0
-	}
-	prog.address += 4
-}
-
-func (prog *Intcode) opAdjustBaseOffset(a int64) {
-	prog.relativeBase += prog.memory[a]
-	prog.address += 2
-}
-
-func (prog *Intcode) opHalt() {
-	prog.address += 1
-	prog.halted = true
-}
-
-func (prog *Intcode) param(modes []int64, n int64) (res int64) {
-	switch modes[n] {
-	case 0:
-		res = prog.memory[prog.address+n+1]
-	case 1:
-		res = prog.address + n + 1
-	case 2:
-		res = prog.memory[prog.address+n+1] + prog.relativeBase
-	}
-	return
-}
-
-func (prog *Intcode) executeInstruction(inst *Instruction) {
-	switch inst.Opcode {
-	case 1:
-		prog.opAdd(
-			prog.param(inst.Modes, 0),
-			prog.param(inst.Modes, 1),
-			prog.param(inst.Modes, 2),
-		)
-	case 2:
-		prog.opMultiply(
-			prog.param(inst.Modes, 0),
-			prog.param(inst.Modes, 1),
-			prog.param(inst.Modes, 2),
-		)
-	case 3:
-		prog.opInput(
-			prog.param(inst.Modes, 0),
-		)
-	case 4:
-		prog.opOutput(
-			prog.param(inst.Modes, 0),
-		)
-	case 5:
-		prog.opJmpTrue(
-			prog.param(inst.Modes, 0),
-			prog.param(inst.Modes, 1),
-		)
-	case 6:
-		prog.opJmpFalse(
-			prog.param(inst.Modes, 0),
-			prog.param(inst.Modes, 1),
-		)
-	case 7:
-		prog.opLessThan(
-			prog.param(inst.Modes, 0),
-			prog.param(inst.Modes, 1),
-			prog.param(inst.Modes, 2),
-		)
-	case 8:
-		prog.opEquals(
-			prog.param(inst.Modes, 0),
-			prog.param(inst.Modes, 1),
-			prog.param(inst.Modes, 2),
-		)
-	case 9:
-		prog.opAdjustBaseOffset(
-			prog.param(inst.Modes, 0),
-		)
-	case 99:
-		prog.opHalt()
-	}
-}
-
-func (prog *Intcode) parseInstruction(num int64) *Instruction {
-	digits := make([]int64, 5)
-	for i := 0; i < 5 && num > 0; i++ {
-		digits[i] = num % 10
-		num /= 10
-	}
-	opcode := digits[1]*10 + digits[0]
-	return &Instruction{
-		Opcode: opcode,
-		Modes:  digits[2:],
-	}
-}
-
-type Point struct {
-	X int
-	Y int
-}
+	"github.com/dcoxall/advent-of-code-2019/13/go/arcade"
+	"github.com/dcoxall/advent-of-code-2019/canvas"
+	"github.com/dcoxall/advent-of-code-2019/intcode/trace"
+)
 
-const (
-	IdPaddle = int64(3)
-	IdBall   = int64(4)
+var (
+	tracing  = trace.Flags()
+	play     = flag.Bool("play", false, "play the game yourself, entering a or d to move left or right")
+	watch    = flag.Bool("watch", false, "draw the game in the terminal as the autopilot plays")
+	strategy = flag.String("strategy", "tracker", "how the autopilot plays: "+strings.Join(arcade.StrategyNames, ", "))
+	delay    = flag.Duration("delay", 20*time.Millisecond, "time to wait between frames when watching")
+	record   = flag.String("record", "", "save the joystick moves to a file")
+	replay   = flag.String("replay", "", "replay the joystick moves saved in a file")
+	pngFile  = flag.String("png", "", "write the final screen to a PNG file")
+	gifFile  = flag.String("gif", "", "write every frame of the game to an animated GIF")
+	cellSize = flag.Int("cell", 4, "size of each tile in pixels")
 )
 
-func cmp(a, b int) int {
-	if a > b {
-		return 1
+func loadJoystick(name string) ([]int64, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
 	}
-	if a < b {
-		return -1
-	}
-	return 0
+	defer f.Close()
+	return arcade.ReadJoystick(f)
 }
 
-func readGameData(game *Intcode) (bool, Point, int64) {
-	var (
-		ok        bool
-		x, y, val int64
-	)
-	if ok, x = game.ReadOutput(); !ok {
-		return false, Point{0, 0}, 0
-	}
-	if ok, y = game.ReadOutput(); !ok {
-		return false, Point{0, 0}, 0
+func saveJoystick(name string, joystick []int64) error {
+	f, err := os.Create(name)
+	if err != nil {
+		return err
 	}
-	if ok, val = game.ReadOutput(); !ok {
-		return false, Point{0, 0}, 0
+	if err := arcade.WriteJoystick(f, joystick); err != nil {
+		f.Close()
+		return err
 	}
-	point := Point{X: int(x), Y: int(y)}
-	return true, point, val
+	return f.Close()
 }
 
 func main() {
+	flag.Parse()
+	defer tracing.Begin()()
+
 	f, _ := os.Open("./inputs/13.txt")
 	defer f.Close()
 
@@ -267,43 +75,77 @@ func main() {
 		}
 	}
 
-	memory[int64(0)] = 2
-
-	intcode := NewIntcodeProg(memory)
-	canvas := make(map[Point]int64)
-	var (
-		ball   Point
-		paddle Point
-		score  int64
-	)
+	game := arcade.New(memory)
+	game.FreePlay()
+	tracing.Attach(game.Machine())
 
-	intcode.Execute()
+	controller, err := arcade.NewStrategy(*strategy)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	switch {
+	case *replay != "":
+		joystick, err := loadJoystick(*replay)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		controller = arcade.Replay(joystick)
+	case *play:
+		controller = arcade.Keyboard(os.Stdin)
+	}
 
-	for !intcode.halted {
-		// read the outputs
-		for ok, point, val := readGameData(intcode); ok; ok, point, val = readGameData(intcode) {
-			if point.X == -1 {
-				score = val
-			} else {
-				switch val {
-				case IdBall:
-					ball = point
-				case IdPaddle:
-					paddle = point
-				}
-				canvas[point] = val
-			}
+	var terminal *arcade.Terminal
+	if *play || *watch {
+		terminal = arcade.NewTerminal(os.Stdout)
+		if *watch {
+			terminal.Delay = *delay
 		}
+		controller = terminal.Watch(controller)
+	}
+
+	// capture the screen every time the joystick is about to move
+	style := canvas.Style[int64]{CellSize: *cellSize, Colour: arcade.Colours}
+	var animation *canvas.Animation[int64]
+	if *gifFile != "" {
+		animation = canvas.NewAnimation(game.Picture(), style)
+		animation.Delay = 2
+		next := controller
+		controller = arcade.Controller(func(a *arcade.Arcade) (int64, error) {
+			animation.Capture()
+			return next.Joystick(a)
+		})
+	}
 
-		// handle inputs
-		intcode.ResumeWith(int64(cmp(ball.X, paddle.X)))
+	if err := game.Play(context.Background(), controller); err != nil {
+		fmt.Println(err)
+		return
 	}
 
-	for ok, point, val := readGameData(intcode); ok; ok, point, val = readGameData(intcode) {
-		if point.X == -1 {
-			score = val
+	if *pngFile != "" {
+		if err := game.Picture().SavePNG(*pngFile, style); err != nil {
+			fmt.Println(err)
+			return
+		}
+	}
+	if animation != nil {
+		animation.Capture()
+		if err := animation.SaveGIF(*gifFile); err != nil {
+			fmt.Println(err)
+			return
+		}
+	}
+
+	if terminal != nil {
+		terminal.Draw(game)
+	}
+	if *record != "" {
+		if err := saveJoystick(*record, game.Joystick()); err != nil {
+			fmt.Println(err)
+			return
 		}
 	}
 
-	fmt.Println(score)
+	fmt.Println(game.Score())
 }
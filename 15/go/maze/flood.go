@@ -0,0 +1,82 @@
+package maze
+
+import (
+	"maps"
+
+	"github.com/dcoxall/advent-of-code-2019/grid"
+)
+
+// Flood is the result of filling a maze with oxygen, which each minute
+// spreads from every filled position to the open positions next to it.
+type Flood struct {
+	maze *Maze
+	// Minutes is how long it takes for the oxygen to reach everywhere it
+	// can.
+	Minutes int
+	// Filled is the minute each position was reached.
+	Filled map[grid.Point]int
+	// Steps lists the positions newly filled each minute, starting with the
+	// sources at minute 0.
+	Steps [][]grid.Point
+}
+
+// Fill floods the maze with oxygen from each of the sources at once. Sources
+// that aren't open are ignored.
+func (m *Maze) Fill(sources ...grid.Point) *Flood {
+	flood := &Flood{maze: m, Filled: make(map[grid.Point]int)}
+
+	frontier := make([]grid.Point, 0, len(sources))
+	for _, p := range sources {
+		if _, filled := flood.Filled[p]; !filled && m.IsOpen(p) {
+			flood.Filled[p] = 0
+			frontier = append(frontier, p)
+		}
+	}
+	if len(frontier) == 0 {
+		return flood
+	}
+	flood.Steps = append(flood.Steps, frontier)
+
+	for minute := 1; ; minute++ {
+		next := make([]grid.Point, 0)
+		for _, p := range frontier {
+			for _, n := range p.Neighbours() {
+				if _, filled := flood.Filled[n]; !filled && m.IsOpen(n) {
+					flood.Filled[n] = minute
+					next = append(next, n)
+				}
+			}
+		}
+		if len(next) == 0 {
+			return flood
+		}
+
+		flood.Steps = append(flood.Steps, next)
+		flood.Minutes = minute
+		frontier = next
+	}
+}
+
+// Frame returns the maze as it is after the given number of minutes, with
+// every filled position shown as Oxygen. It can be drawn with the canvas
+// package like any other map of cells.
+func (f *Flood) Frame(minute int) map[grid.Point]Cell {
+	frame := maps.Clone(f.maze.Cells)
+	for p, filled := range f.Filled {
+		if filled <= minute {
+			frame[p] = Oxygen
+		}
+	}
+	return frame
+}
+
+// Unreached returns the open positions the oxygen never gets to.
+func (f *Flood) Unreached() []grid.Point {
+	unreached := make([]grid.Point, 0)
+	for _, p := range f.maze.Open() {
+		if _, filled := f.Filled[p]; !filled {
+			unreached = append(unreached, p)
+		}
+	}
+	return unreached
+}
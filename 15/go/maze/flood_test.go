@@ -0,0 +1,96 @@
+package maze
+
+import (
+	"testing"
+
+	"github.com/dcoxall/advent-of-code-2019/grid"
+)
+
+// a loop, where the longest path from the oxygen goes all the way round but
+// the oxygen goes both ways at once
+const loop = `
+#######
+#O....#
+#.###.#
+#.....#
+#######
+`
+
+func TestFillLoop(t *testing.T) {
+	m := parse(loop)
+	flood := m.Fill(m.Oxygen)
+
+	if flood.Minutes != 6 {
+		t.Errorf("expected 6 minutes but got %d", flood.Minutes)
+	}
+	if len(flood.Filled) != 12 || len(flood.Unreached()) != 0 {
+		t.Errorf("expected all 12 positions filled but got %d, missing %v", len(flood.Filled), flood.Unreached())
+	}
+	if minute := flood.Filled[grid.Point{X: 5, Y: 3}]; minute != 6 {
+		t.Errorf("expected the far corner to fill at 6 but got %d", minute)
+	}
+
+	// two at a time spread until they meet at the far corner
+	sizes := []int{1, 2, 2, 2, 2, 2, 1}
+	if len(flood.Steps) != len(sizes) {
+		t.Fatalf("expected %d steps but got %d", len(sizes), len(flood.Steps))
+	}
+	for minute, size := range sizes {
+		if len(flood.Steps[minute]) != size {
+			t.Errorf("minute %d: expected %d filled but got %d", minute, size, len(flood.Steps[minute]))
+		}
+	}
+}
+
+func TestFillSources(t *testing.T) {
+	m := parse(`
+#########
+#O..#...#
+#.#.#.#.#
+#...#...#
+#########
+`)
+	// the right hand loop is sealed off from the oxygen
+	flood := m.Fill(m.Oxygen)
+	if flood.Minutes != 4 {
+		t.Errorf("expected 4 minutes but got %d", flood.Minutes)
+	}
+	if len(flood.Unreached()) != 8 {
+		t.Errorf("expected 8 unreached but got %d", len(flood.Unreached()))
+	}
+
+	// with a second source in the other loop everything fills
+	flood = m.Fill(m.Oxygen, grid.Point{X: 5, Y: 1}, grid.Point{X: 0, Y: 0})
+	if flood.Minutes != 4 || len(flood.Unreached()) != 0 {
+		t.Errorf("expected everything filled in 4 minutes but got %d, missing %v", flood.Minutes, flood.Unreached())
+	}
+	if len(flood.Steps[0]) != 2 {
+		t.Errorf("expected the wall to be ignored as a source but got %v", flood.Steps[0])
+	}
+
+	if flood := m.Fill(); flood.Minutes != 0 || len(flood.Steps) != 0 {
+		t.Errorf("expected nothing to happen without a source but got %d minutes", flood.Minutes)
+	}
+}
+
+func TestFrame(t *testing.T) {
+	m := parse(loop)
+	flood := m.Fill(m.Oxygen)
+
+	frame := flood.Frame(1)
+	oxygen := 0
+	for _, cell := range frame {
+		if cell == Oxygen {
+			oxygen++
+		}
+	}
+	if oxygen != 3 {
+		t.Errorf("expected 3 positions with oxygen after a minute but got %d", oxygen)
+	}
+	if frame[grid.Point{X: 0, Y: 0}] != Wall || len(frame) != len(m.Cells) {
+		t.Error("expected the frame to include the rest of the maze")
+	}
+	if m.Cells[grid.Point{X: 2, Y: 1}] != Open {
+		t.Error("expected the maze to be left alone")
+	}
+}
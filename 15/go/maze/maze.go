@@ -0,0 +1,190 @@
+// Package maze maps out the area explored by the repair droid of Day 15 and
+// answers questions about getting around it.
+package maze
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dcoxall/advent-of-code-2019/grid"
+	"github.com/dcoxall/advent-of-code-2019/intcode"
+)
+
+// Cell is what the droid found at a position. The values are the status codes
+// the droid reports after trying to move.
+type Cell int64
+
+const (
+	Wall Cell = iota
+	Open
+	Oxygen
+)
+
+func (c Cell) String() string {
+	switch c {
+	case Wall:
+		return "Wall"
+	case Open:
+		return "Open"
+	case Oxygen:
+		return "Oxygen"
+	}
+	return fmt.Sprintf("Cell(%d)", int64(c))
+}
+
+// Maze is a fully mapped area. Any position not in Cells was never reached,
+// which for a complete map means it is surrounded by walls.
+type Maze struct {
+	Cells  map[grid.Point]Cell
+	Start  grid.Point
+	Oxygen grid.Point
+	// HasOxygen is false if the oxygen system wasn't found.
+	HasOxygen bool
+}
+
+func New(start grid.Point) *Maze {
+	return &Maze{
+		Cells: map[grid.Point]Cell{start: Open},
+		Start: start,
+	}
+}
+
+// IsOpen reports whether the droid can move to the position, which includes
+// the oxygen system.
+func (m *Maze) IsOpen(p grid.Point) bool {
+	cell, ok := m.Cells[p]
+	return ok && cell != Wall
+}
+
+// Walls returns every wall that has been found.
+func (m *Maze) Walls() []grid.Point {
+	return m.find(func(c Cell) bool { return c == Wall })
+}
+
+// Open returns every position the droid can move to.
+func (m *Maze) Open() []grid.Point {
+	return m.find(func(c Cell) bool { return c != Wall })
+}
+
+func (m *Maze) find(match func(Cell) bool) []grid.Point {
+	points := make([]grid.Point, 0)
+	for p, cell := range m.Cells {
+		if match(cell) {
+			points = append(points, p)
+		}
+	}
+	return points
+}
+
+// Distances returns the fewest moves needed to reach every open position from
+// the one given. Positions that can't be reached are left out.
+func (m *Maze) Distances(from grid.Point) map[grid.Point]int {
+	distances := make(map[grid.Point]int)
+	if !m.IsOpen(from) {
+		return distances
+	}
+
+	distances[from] = 0
+	queue := []grid.Point{from}
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		for _, next := range p.Neighbours() {
+			if _, seen := distances[next]; seen || !m.IsOpen(next) {
+				continue
+			}
+			distances[next] = distances[p] + 1
+			queue = append(queue, next)
+		}
+	}
+	return distances
+}
+
+// commands are what the droid needs to be sent to move in each direction
+var commands = map[grid.Direction]int64{
+	grid.Up:    1,
+	grid.Down:  2,
+	grid.Left:  3,
+	grid.Right: 4,
+}
+
+// MazeMapper maps the area by driving the droid to every position it can
+// reach. Rather than walking the droid back and forth it explores breadth
+// first, cloning the droid at each new position to try each direction from
+// there.
+type MazeMapper struct {
+	prog    *intcode.Intcode
+	explore func(p grid.Point, cell Cell)
+}
+
+// NewMazeMapper creates a mapper for the droid's program.
+func NewMazeMapper(program []int64) *MazeMapper {
+	return &MazeMapper{prog: intcode.New(program)}
+}
+
+// Machine returns the droid's machine, so that it can be traced. The clones
+// made while exploring share its tracer.
+func (m *MazeMapper) Machine() *intcode.Intcode {
+	return m.prog
+}
+
+// OnExplore calls fn with each position as it is found.
+func (m *MazeMapper) OnExplore(fn func(p grid.Point, cell Cell)) {
+	m.explore = fn
+}
+
+type droid struct {
+	pos  grid.Point
+	prog *intcode.Intcode
+}
+
+// Map explores everywhere the droid can reach from where it starts, which is
+// taken to be the origin.
+func (m *MazeMapper) Map(ctx context.Context) (*Maze, error) {
+	maze := New(grid.Point{})
+	if _, _, err := m.prog.Run(ctx); err != nil {
+		return nil, err
+	}
+
+	queue := []droid{{pos: maze.Start, prog: m.prog}}
+	for len(queue) > 0 {
+		d := queue[0]
+		queue = queue[1:]
+
+		for _, dir := range grid.Directions {
+			next := d.pos.Move(dir)
+			if _, seen := maze.Cells[next]; seen {
+				continue
+			}
+
+			prog := d.prog.Clone()
+			prog.Input().(*intcode.Queue).Push(commands[dir])
+			if _, _, err := prog.Run(ctx); err != nil {
+				return nil, err
+			}
+			ok, status := prog.ReadOutput()
+			if !ok {
+				return nil, fmt.Errorf("maze: no status from the droid moving %s from %v", dir, d.pos)
+			}
+			cell := Cell(status)
+			if cell < Wall || cell > Oxygen {
+				return nil, fmt.Errorf("maze: unknown status %d from the droid moving %s from %v", status, dir, d.pos)
+			}
+
+			maze.Cells[next] = cell
+			if m.explore != nil {
+				m.explore(next, cell)
+			}
+			if cell == Wall {
+				continue
+			}
+			if cell == Oxygen {
+				maze.Oxygen = next
+				maze.HasOxygen = true
+			}
+			queue = append(queue, droid{pos: next, prog: prog})
+		}
+	}
+
+	return maze, nil
+}
@@ -0,0 +1,109 @@
+package maze
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/dcoxall/advent-of-code-2019/grid"
+	"github.com/dcoxall/advent-of-code-2019/intcode"
+)
+
+// parse builds a maze from a drawing where # is a wall, O the oxygen system,
+// D the start and anything else open.
+func parse(drawing string) *Maze {
+	m := &Maze{Cells: make(map[grid.Point]Cell)}
+	for y, row := range strings.Split(strings.Trim(drawing, "\n"), "\n") {
+		for x, c := range row {
+			p := grid.Point{X: x, Y: y}
+			switch c {
+			case '#':
+				m.Cells[p] = Wall
+			case 'O':
+				m.Cells[p] = Oxygen
+				m.Oxygen = p
+				m.HasOxygen = true
+			case 'D':
+				m.Cells[p] = Open
+				m.Start = p
+			default:
+				m.Cells[p] = Open
+			}
+		}
+	}
+	return m
+}
+
+func TestDistances(t *testing.T) {
+	m := parse(`
+#######
+#D....#
+#.###.#
+#.#O..#
+#.###.#
+#.....#
+#######
+`)
+	distances := m.Distances(m.Start)
+
+	expected := map[grid.Point]int{
+		m.Oxygen:     8,
+		{X: 5, Y: 5}: 8,
+		{X: 3, Y: 5}: 6,
+		{X: 5, Y: 1}: 4,
+		{X: 1, Y: 5}: 4,
+		{X: 4, Y: 3}: 7,
+	}
+	for p, d := range expected {
+		if distances[p] != d {
+			t.Errorf("%v: expected %d but got %d", p, d, distances[p])
+		}
+	}
+	if len(distances) != len(m.Open()) {
+		t.Errorf("expected every one of %d open cells to be reached but got %d", len(m.Open()), len(distances))
+	}
+	if len(m.Walls()) != 31 {
+		t.Errorf("expected 31 walls but got %d", len(m.Walls()))
+	}
+
+	if d := m.Distances(grid.Point{}); len(d) != 0 {
+		t.Errorf("expected nothing from a wall but got %v", d)
+	}
+}
+
+func TestMap(t *testing.T) {
+	f, err := os.Open("../../../inputs/15.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	program, err := intcode.Parse(f)
+	f.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	explored := 0
+	mapper := NewMazeMapper(program)
+	mapper.OnExplore(func(grid.Point, Cell) { explored++ })
+
+	m, err := mapper.Map(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !m.HasOxygen {
+		t.Fatal("expected to find the oxygen system")
+	}
+	if explored != len(m.Cells)-1 {
+		t.Errorf("expected to hear about %d cells but heard about %d", len(m.Cells)-1, explored)
+	}
+
+	// every open cell is surrounded by known cells, so nothing was missed
+	for _, p := range m.Open() {
+		for _, n := range p.Neighbours() {
+			if _, ok := m.Cells[n]; !ok {
+				t.Fatalf("%v next to %v was never explored", n, p)
+			}
+		}
+	}
+}
@@ -0,0 +1,139 @@
+// Package canvas renders sparse grids, where only some points have a value,
+// as text.
+package canvas
+
+import (
+	"io"
+	"strings"
+
+	"github.com/dcoxall/advent-of-code-2019/grid"
+)
+
+// Rect is a rectangle of points with both corners included.
+type Rect struct {
+	Min grid.Point
+	Max grid.Point
+}
+
+func (r Rect) Width() int {
+	return r.Max.X - r.Min.X + 1
+}
+
+func (r Rect) Height() int {
+	return r.Max.Y - r.Min.Y + 1
+}
+
+// Contains reports whether the point is within the rectangle.
+func (r Rect) Contains(p grid.Point) bool {
+	return p.X >= r.Min.X && p.X <= r.Max.X && p.Y >= r.Min.Y && p.Y <= r.Max.Y
+}
+
+// Canvas draws the values of a sparse grid using a rune for each. Points
+// without a value are drawn as Empty.
+type Canvas[T any] struct {
+	cells   map[grid.Point]T
+	glyph   func(T) rune
+	markers map[grid.Point]rune
+
+	// Empty is drawn for points without a value, a space by default.
+	Empty rune
+}
+
+// New creates a canvas over the cells, using glyph to choose how each value
+// is drawn. The cells are read each time the canvas is drawn so it can be
+// drawn again as they change.
+func New[T any](cells map[grid.Point]T, glyph func(T) rune) *Canvas[T] {
+	return &Canvas[T]{
+		cells:   cells,
+		glyph:   glyph,
+		markers: make(map[grid.Point]rune),
+		Empty:   ' ',
+	}
+}
+
+// Palette creates a glyph function that looks values up in runes, drawing
+// anything missing as fallback.
+func Palette[T comparable](runes map[T]rune, fallback rune) func(T) rune {
+	return func(val T) rune {
+		if r, ok := runes[val]; ok {
+			return r
+		}
+		return fallback
+	}
+}
+
+// Mark draws the rune at the point over whatever is there, such as to show
+// where a robot is. A point can only have one marker.
+func (c *Canvas[T]) Mark(p grid.Point, r rune) {
+	c.markers[p] = r
+}
+
+// Unmark removes the marker from a point.
+func (c *Canvas[T]) Unmark(p grid.Point) {
+	delete(c.markers, p)
+}
+
+// ClearMarkers removes every marker.
+func (c *Canvas[T]) ClearMarkers() {
+	clear(c.markers)
+}
+
+// Bounds returns the smallest rectangle containing every cell and marker. It
+// returns false if there is nothing to draw.
+func (c *Canvas[T]) Bounds() (Rect, bool) {
+	var bounds Rect
+	found := false
+
+	extend := func(p grid.Point) {
+		if !found {
+			bounds = Rect{Min: p, Max: p}
+			found = true
+			return
+		}
+		bounds.Min.X = min(bounds.Min.X, p.X)
+		bounds.Min.Y = min(bounds.Min.Y, p.Y)
+		bounds.Max.X = max(bounds.Max.X, p.X)
+		bounds.Max.Y = max(bounds.Max.Y, p.Y)
+	}
+
+	for p := range c.cells {
+		extend(p)
+	}
+	for p := range c.markers {
+		extend(p)
+	}
+	return bounds, found
+}
+
+// At returns the rune drawn at a point.
+func (c *Canvas[T]) At(p grid.Point) rune {
+	if r, ok := c.markers[p]; ok {
+		return r
+	}
+	if val, ok := c.cells[p]; ok {
+		return c.glyph(val)
+	}
+	return c.Empty
+}
+
+// String draws the canvas with a line for each row, top to bottom.
+func (c *Canvas[T]) String() string {
+	bounds, ok := c.Bounds()
+	if !ok {
+		return ""
+	}
+
+	var sb strings.Builder
+	for y := bounds.Min.Y; y <= bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x <= bounds.Max.X; x++ {
+			sb.WriteRune(c.At(grid.Point{X: x, Y: y}))
+		}
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+func (c *Canvas[T]) WriteTo(w io.Writer) (int64, error) {
+	n, err := io.WriteString(w, c.String())
+	return int64(n), err
+}
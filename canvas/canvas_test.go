@@ -0,0 +1,75 @@
+package canvas
+
+import (
+	"testing"
+
+	"github.com/dcoxall/advent-of-code-2019/grid"
+)
+
+func TestRender(t *testing.T) {
+	cells := map[grid.Point]int{
+		{X: -2, Y: -1}: 1,
+		{X: 0, Y: 0}:   1,
+		{X: 1, Y: 1}:   2,
+		{X: -1, Y: 1}:  0,
+	}
+	c := New(cells, Palette(map[int]rune{1: '#', 2: 'O'}, '.'))
+
+	bounds, ok := c.Bounds()
+	if !ok {
+		t.Fatal("expected bounds")
+	}
+	if expected := (Rect{Min: grid.Point{X: -2, Y: -1}, Max: grid.Point{X: 1, Y: 1}}); bounds != expected {
+		t.Errorf("expected bounds %v but got %v", expected, bounds)
+	}
+	if bounds.Width() != 4 || bounds.Height() != 3 {
+		t.Errorf("expected 4x3 but got %dx%d", bounds.Width(), bounds.Height())
+	}
+
+	expected := "#   \n" +
+		"  # \n" +
+		" . O\n"
+	if out := c.String(); out != expected {
+		t.Errorf("expected\n%q\nbut got\n%q", expected, out)
+	}
+}
+
+func TestMarkers(t *testing.T) {
+	cells := map[grid.Point]bool{
+		{X: 0, Y: 0}: true,
+		{X: 1, Y: 0}: true,
+	}
+	c := New(cells, func(wall bool) rune {
+		if wall {
+			return '#'
+		}
+		return '.'
+	})
+	c.Empty = '?'
+
+	// markers are drawn over cells and outside of them
+	c.Mark(grid.Point{X: 1, Y: 0}, 'D')
+	c.Mark(grid.Point{X: -1, Y: -2}, 'S')
+
+	expected := "S??\n" +
+		"???\n" +
+		"?#D\n"
+	if out := c.String(); out != expected {
+		t.Errorf("expected\n%q\nbut got\n%q", expected, out)
+	}
+
+	c.Unmark(grid.Point{X: -1, Y: -2})
+	if out := c.String(); out != "#D\n" {
+		t.Errorf("expected %q but got %q", "#D\n", out)
+	}
+}
+
+func TestEmpty(t *testing.T) {
+	c := New(map[grid.Point]int{}, Palette(map[int]rune{}, '#'))
+	if _, ok := c.Bounds(); ok {
+		t.Error("expected no bounds")
+	}
+	if out := c.String(); out != "" {
+		t.Errorf("expected nothing but got %q", out)
+	}
+}
@@ -0,0 +1,252 @@
+package canvas
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"image/png"
+	"io"
+	"maps"
+	"os"
+
+	"github.com/dcoxall/advent-of-code-2019/grid"
+)
+
+// Style says how a canvas is drawn as an image.
+type Style[T any] struct {
+	// CellSize is the width and height in pixels of each point, 1 if not
+	// set.
+	CellSize int
+	// Colour chooses the colour of each value, white for all of them if
+	// not set.
+	Colour func(T) color.Color
+	// Empty is the colour of points without a value, black if not set.
+	Empty color.Color
+	// Marker is the colour of every marker, red if not set.
+	Marker color.Color
+}
+
+func (s Style[T]) withDefaults() Style[T] {
+	if s.CellSize < 1 {
+		s.CellSize = 1
+	}
+	if s.Colour == nil {
+		s.Colour = func(T) color.Color { return color.White }
+	}
+	if s.Empty == nil {
+		s.Empty = color.Black
+	}
+	if s.Marker == nil {
+		s.Marker = color.RGBA{R: 0xff, A: 0xff}
+	}
+	return s
+}
+
+// Colours creates a colour function that looks values up in colours, using
+// fallback for anything missing.
+func Colours[T comparable](colours map[T]color.Color, fallback color.Color) func(T) color.Color {
+	return func(val T) color.Color {
+		if c, ok := colours[val]; ok {
+			return c
+		}
+		return fallback
+	}
+}
+
+// Image draws the canvas with each point as a square of pixels.
+func (c *Canvas[T]) Image(style Style[T]) image.Image {
+	style = style.withDefaults()
+	bounds, ok := c.Bounds()
+	if !ok {
+		return image.NewRGBA(image.Rectangle{})
+	}
+	img := image.NewRGBA(pixels(bounds, style.CellSize))
+	paint(img, bounds, c.cells, c.markers, style)
+	return img
+}
+
+// WritePNG writes the canvas as a PNG image.
+func (c *Canvas[T]) WritePNG(w io.Writer, style Style[T]) error {
+	return png.Encode(w, c.Image(style))
+}
+
+// SavePNG writes the canvas as a PNG image to the named file.
+func (c *Canvas[T]) SavePNG(name string, style Style[T]) error {
+	return save(name, func(w io.Writer) error { return c.WritePNG(w, style) })
+}
+
+// pixels is the size of the image needed to draw the points in bounds.
+func pixels(bounds Rect, size int) image.Rectangle {
+	return image.Rect(0, 0, bounds.Width()*size, bounds.Height()*size)
+}
+
+// paint fills in every point within bounds, with the top left of bounds at
+// the top left of the image.
+func paint[T any](img draw.Image, bounds Rect, cells map[grid.Point]T, markers map[grid.Point]rune, style Style[T]) {
+	size := style.CellSize
+	for y := bounds.Min.Y; y <= bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x <= bounds.Max.X; x++ {
+			p := grid.Point{X: x, Y: y}
+			colour := style.Empty
+			if _, ok := markers[p]; ok {
+				colour = style.Marker
+			} else if val, ok := cells[p]; ok {
+				colour = style.Colour(val)
+			}
+
+			px := (x - bounds.Min.X) * size
+			py := (y - bounds.Min.Y) * size
+			cell := image.Rect(px, py, px+size, py+size)
+			draw.Draw(img, cell, image.NewUniform(colour), image.Point{}, draw.Src)
+		}
+	}
+}
+
+// frame is what changed on the canvas since the previous frame, which keeps
+// a long recording from holding a full copy of the canvas for every frame.
+type frame[T any] struct {
+	changed map[grid.Point]T
+	removed []grid.Point
+	markers map[grid.Point]rune
+}
+
+// Animation records frames of a canvas as it changes so that they can be
+// written as an animated GIF.
+type Animation[T comparable] struct {
+	canvas *Canvas[T]
+	style  Style[T]
+	frames []frame[T]
+	last   map[grid.Point]T
+
+	// Delay is the time each frame is shown for, in 100ths of a second.
+	Delay int
+}
+
+// NewAnimation starts recording the canvas. Frames are only taken when
+// Capture is called.
+func NewAnimation[T comparable](c *Canvas[T], style Style[T]) *Animation[T] {
+	return &Animation[T]{
+		canvas: c,
+		style:  style.withDefaults(),
+		last:   make(map[grid.Point]T),
+		Delay:  5,
+	}
+}
+
+// Capture takes a frame of the canvas as it is now.
+func (a *Animation[T]) Capture() {
+	f := frame[T]{
+		changed: make(map[grid.Point]T),
+		markers: maps.Clone(a.canvas.markers),
+	}
+	for p, val := range a.canvas.cells {
+		if prev, ok := a.last[p]; !ok || prev != val {
+			f.changed[p] = val
+			a.last[p] = val
+		}
+	}
+	for p := range a.last {
+		if _, ok := a.canvas.cells[p]; !ok {
+			f.removed = append(f.removed, p)
+			delete(a.last, p)
+		}
+	}
+	a.frames = append(a.frames, f)
+}
+
+// Frames returns how many frames have been captured.
+func (a *Animation[T]) Frames() int {
+	return len(a.frames)
+}
+
+// WriteGIF writes every captured frame as an animated GIF. Every frame
+// covers the area of all of them so that the picture doesn't shift about as
+// the canvas grows. GIFs are limited to 256 colours.
+func (a *Animation[T]) WriteGIF(w io.Writer) error {
+	if len(a.frames) == 0 {
+		return fmt.Errorf("canvas: no frames to write")
+	}
+
+	var bounds Rect
+	found := false
+	colours := newPalette(a.style.Empty, a.style.Marker)
+	extend := func(p grid.Point) {
+		if !found {
+			bounds = Rect{Min: p, Max: p}
+			found = true
+			return
+		}
+		bounds.Min.X = min(bounds.Min.X, p.X)
+		bounds.Min.Y = min(bounds.Min.Y, p.Y)
+		bounds.Max.X = max(bounds.Max.X, p.X)
+		bounds.Max.Y = max(bounds.Max.Y, p.Y)
+	}
+	for _, f := range a.frames {
+		for p, val := range f.changed {
+			extend(p)
+			colours.add(a.style.Colour(val))
+		}
+		for p := range f.markers {
+			extend(p)
+		}
+	}
+	if len(colours.colours) > 256 {
+		return fmt.Errorf("canvas: %d colours is too many for a GIF", len(colours.colours))
+	}
+
+	anim := &gif.GIF{}
+	cells := make(map[grid.Point]T)
+	for _, f := range a.frames {
+		for _, p := range f.removed {
+			delete(cells, p)
+		}
+		maps.Copy(cells, f.changed)
+
+		img := image.NewPaletted(pixels(bounds, a.style.CellSize), colours.colours)
+		paint(img, bounds, cells, f.markers, a.style)
+		anim.Image = append(anim.Image, img)
+		anim.Delay = append(anim.Delay, a.Delay)
+	}
+	return gif.EncodeAll(w, anim)
+}
+
+// SaveGIF writes every captured frame as an animated GIF to the named file.
+func (a *Animation[T]) SaveGIF(name string) error {
+	return save(name, a.WriteGIF)
+}
+
+func save(name string, write func(io.Writer) error) error {
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// palette collects the distinct colours used by an animation.
+type palette struct {
+	colours color.Palette
+	seen    map[color.RGBA64]bool
+}
+
+func newPalette(colours ...color.Color) *palette {
+	p := &palette{seen: make(map[color.RGBA64]bool)}
+	for _, c := range colours {
+		p.add(c)
+	}
+	return p
+}
+
+func (p *palette) add(c color.Color) {
+	key := color.RGBA64Model.Convert(c).(color.RGBA64)
+	if !p.seen[key] {
+		p.seen[key] = true
+		p.colours = append(p.colours, c)
+	}
+}
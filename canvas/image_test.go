@@ -0,0 +1,142 @@
+package canvas
+
+import (
+	"bytes"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"testing"
+
+	"github.com/dcoxall/advent-of-code-2019/grid"
+)
+
+var white = color.RGBA{0xff, 0xff, 0xff, 0xff}
+
+func same(a, b color.Color) bool {
+	r1, g1, b1, a1 := a.RGBA()
+	r2, g2, b2, a2 := b.RGBA()
+	return r1 == r2 && g1 == g2 && b1 == b2 && a1 == a2
+}
+
+func TestWritePNG(t *testing.T) {
+	cells := map[grid.Point]int64{
+		{X: -1, Y: -1}: 1,
+		{X: 1, Y: 0}:   0,
+	}
+	c := New(cells, Palette(map[int64]rune{1: '#'}, ' '))
+	c.Mark(grid.Point{X: 0, Y: 0}, 'D')
+	style := Style[int64]{
+		CellSize: 2,
+		Colour:   Colours(map[int64]color.Color{1: white}, color.Black),
+	}
+
+	var buf bytes.Buffer
+	if err := c.WritePNG(&buf, style); err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if size := img.Bounds().Size(); size.X != 6 || size.Y != 4 {
+		t.Fatalf("expected 6x4 but got %dx%d", size.X, size.Y)
+	}
+	pixels := []struct {
+		x, y   int
+		colour color.Color
+	}{
+		{0, 0, white},
+		{1, 1, white},
+		{2, 0, color.Black},
+		{2, 2, color.RGBA{R: 0xff, A: 0xff}},
+		{5, 3, color.Black},
+	}
+	for _, px := range pixels {
+		if c := img.At(px.x, px.y); !same(c, px.colour) {
+			t.Errorf("pixel %d,%d: expected %v but got %v", px.x, px.y, px.colour, c)
+		}
+	}
+}
+
+func TestWriteGIF(t *testing.T) {
+	cells := map[grid.Point]int64{{X: 0, Y: 0}: 1}
+	c := New(cells, Palette(map[int64]rune{1: '#'}, ' '))
+	anim := NewAnimation(c, Style[int64]{
+		Colour: Colours(map[int64]color.Color{1: white}, color.Black),
+	})
+
+	anim.Capture()
+	cells[grid.Point{X: -2, Y: 1}] = 1
+	anim.Capture()
+	delete(cells, grid.Point{X: 0, Y: 0})
+	anim.Capture()
+
+	var buf bytes.Buffer
+	if err := anim.WriteGIF(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out, err := gif.DecodeAll(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(out.Image) != 3 {
+		t.Fatalf("expected 3 frames but got %d", len(out.Image))
+	}
+	// every frame covers the points of all of them
+	for i, frame := range out.Image {
+		if size := frame.Bounds().Size(); size.X != 3 || size.Y != 2 {
+			t.Errorf("frame %d: expected 3x2 but got %dx%d", i, size.X, size.Y)
+		}
+	}
+
+	// the origin is at 2,0 and the new point at 0,1
+	expected := []struct{ origin, added color.Color }{
+		{white, color.Black},
+		{white, white},
+		{color.Black, white},
+	}
+	for i, e := range expected {
+		if c := out.Image[i].At(2, 0); !same(c, e.origin) {
+			t.Errorf("frame %d: expected %v at the origin but got %v", i, e.origin, c)
+		}
+		if c := out.Image[i].At(0, 1); !same(c, e.added) {
+			t.Errorf("frame %d: expected %v at the added point but got %v", i, e.added, c)
+		}
+	}
+}
+
+func TestZeroStyle(t *testing.T) {
+	cells := map[grid.Point]int{{X: 0, Y: 0}: 1, {X: 2, Y: 0}: 2}
+	c := New(cells, Palette(map[int]rune{1: '#'}, '?'))
+
+	var buf bytes.Buffer
+	if err := c.WritePNG(&buf, Style[int]{}); err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// every value is white on black, one pixel each
+	for x, colour := range []color.Color{white, color.Black, white} {
+		if c := img.At(x, 0); !same(c, colour) {
+			t.Errorf("pixel %d,0: expected %v but got %v", x, colour, c)
+		}
+	}
+
+	anim := NewAnimation(c, Style[int]{})
+	anim.Capture()
+	buf.Reset()
+	if err := anim.WriteGIF(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out, err := gif.DecodeAll(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c := out.Image[0].At(2, 0); !same(c, white) {
+		t.Errorf("expected white but got %v", c)
+	}
+}
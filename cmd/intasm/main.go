@@ -0,0 +1,56 @@
+// Command intasm assembles a program written in the language printed by
+// intdis into the comma separated format of the puzzle inputs.
+//
+//	$ go run ./cmd/intasm [-o program.txt] program.asm
+//
+// The source is read from standard input when no file is given and the
+// program is written to standard output unless -o is used.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/dcoxall/advent-of-code-2019/intcode"
+	"github.com/dcoxall/advent-of-code-2019/intcode/asm"
+)
+
+func main() {
+	output := flag.String("o", "", "write the program to this file")
+	flag.Parse()
+
+	var rdr io.Reader = os.Stdin
+	if path := flag.Arg(0); path != "" {
+		f, err := os.Open(path)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		rdr = f
+	}
+
+	program, err := asm.Assemble(rdr)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	if err := intcode.Format(w, program); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,448 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dcoxall/advent-of-code-2019/intcode"
+	"github.com/dcoxall/advent-of-code-2019/intcode/asm"
+)
+
+// breakpoint stops execution before the instruction at an address, or before
+// any instruction with a particular opcode.
+type breakpoint struct {
+	address int64
+	opcode  int64
+	byOp    bool
+}
+
+func (b breakpoint) String() string {
+	if b.byOp {
+		return fmt.Sprintf("break on %s", intcode.Mnemonic(b.opcode))
+	}
+	return fmt.Sprintf("break at %d", b.address)
+}
+
+// snapshot remembers how many outputs there were so that restoring also
+// rewinds the output history.
+type snapshot struct {
+	*intcode.Snapshot
+	outputs int
+}
+
+type debugger struct {
+	prog   *intcode.Intcode
+	input  *intcode.Queue
+	output *intcode.Queue
+	out    io.Writer
+
+	breakpoints map[int]breakpoint
+	watchpoints map[int]int64
+	nextID      int
+
+	snapshots []snapshot
+	outputs   []int64
+}
+
+func newDebugger(program []int64, out io.Writer) *debugger {
+	input, output := intcode.NewQueue(), intcode.NewQueue()
+	return &debugger{
+		prog:        intcode.NewWithIO(program, input, output),
+		input:       input,
+		output:      output,
+		out:         out,
+		breakpoints: make(map[int]breakpoint),
+		watchpoints: make(map[int]int64),
+		nextID:      1,
+	}
+}
+
+type command struct {
+	names []string
+	usage string
+	help  string
+	run   func(d *debugger, args []string) error
+}
+
+// commands is filled in by init as help needs to refer back to it
+var commands []command
+
+func init() {
+	commands = []command{
+		{[]string{"step", "s"}, "step [n]", "execute n instructions, 1 by default", (*debugger).step},
+		{[]string{"continue", "c"}, "continue", "run until a breakpoint, watchpoint, halt, fault or missing input", (*debugger).cont},
+		{[]string{"break", "b"}, "break <addr> | break op <op>", "stop before an address or any instruction with the opcode", (*debugger).addBreakpoint},
+		{[]string{"watch", "w"}, "watch <addr>", "stop after the value at an address changes", (*debugger).addWatchpoint},
+		{[]string{"delete", "d"}, "delete <id> | delete all", "remove breakpoints and watchpoints", (*debugger).delete},
+		{[]string{"list", "l"}, "list", "show breakpoints and watchpoints", (*debugger).list},
+		{[]string{"print", "p"}, "print <addr> [count]", "show memory", (*debugger).print},
+		{[]string{"set"}, "set <addr> <value>", "change memory", (*debugger).set},
+		{[]string{"rb"}, "rb [value]", "show or change the relative base", (*debugger).relativeBase},
+		{[]string{"input", "i"}, "input <value>... | input -a <text>", "queue input values, or text followed by a newline", (*debugger).queueInput},
+		{[]string{"output", "o"}, "output", "show the outputs produced so far", (*debugger).showOutput},
+		{[]string{"dis"}, "dis [addr] [count]", "disassemble, from the current address by default", (*debugger).disassemble},
+		{[]string{"info", "r"}, "info", "show the state of the machine", (*debugger).info},
+		{[]string{"snapshot", "snap"}, "snapshot", "save the state of the machine", (*debugger).snapshot},
+		{[]string{"restore"}, "restore [n]", "go back to a snapshot, the latest by default", (*debugger).restore},
+		{[]string{"help", "h", "?"}, "help", "show this list", (*debugger).help},
+	}
+}
+
+func (d *debugger) exec(line string) error {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return nil
+	}
+	for _, cmd := range commands {
+		for _, name := range cmd.names {
+			if name == fields[0] {
+				return cmd.run(d, fields[1:])
+			}
+		}
+	}
+	return fmt.Errorf("unknown command %q, try help", fields[0])
+}
+
+func (d *debugger) printf(format string, args ...interface{}) {
+	fmt.Fprintf(d.out, format, args...)
+}
+
+func parseInt(s string) (int64, error) {
+	val, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a number", s)
+	}
+	return val, nil
+}
+
+// decode decodes the instruction at addr straight out of memory.
+func (d *debugger) decode(addr int64) asm.Line {
+	mem := d.prog.Memory()
+	words := make([]int64, 4)
+	for i := range words {
+		words[i] = mem.Read(addr + int64(i))
+	}
+	line := asm.DecodeAt(words, 0)
+	line.Address = addr
+	return line
+}
+
+// collect moves anything the machine has output into the history.
+func (d *debugger) collect() {
+	for val, ok := d.output.Read(); ok; val, ok = d.output.Read() {
+		d.outputs = append(d.outputs, val)
+		d.printf("output: %d\n", val)
+	}
+}
+
+// advance executes one instruction and reports anything worth stopping for.
+func (d *debugger) advance() (bool, error) {
+	watched := make(map[int]int64, len(d.watchpoints))
+	mem := d.prog.Memory()
+	for id, addr := range d.watchpoints {
+		watched[id] = mem.Read(addr)
+	}
+
+	state, inst, err := d.prog.Step()
+	d.collect()
+
+	stop := false
+	for id, before := range watched {
+		addr := d.watchpoints[id]
+		if after := mem.Read(addr); after != before {
+			d.printf("watchpoint %d: [%d] changed from %d to %d at %d\n", id, addr, before, after, inst.Address)
+			stop = true
+		}
+	}
+
+	switch state {
+	case intcode.NeedsInput:
+		d.printf("waiting for input at %d\n", inst.Address)
+		return true, nil
+	case intcode.Halted:
+		d.printf("halted at %d\n", inst.Address)
+		return true, nil
+	case intcode.Faulted:
+		return true, err
+	}
+	return stop, nil
+}
+
+func (d *debugger) step(args []string) error {
+	count := int64(1)
+	if len(args) > 0 {
+		n, err := parseInt(args[0])
+		if err != nil {
+			return err
+		}
+		count = n
+	}
+
+	for i := int64(0); i < count; i++ {
+		if stop, err := d.advance(); stop || err != nil {
+			return err
+		}
+	}
+	d.printf("%s\n", d.decode(d.prog.Address()))
+	return nil
+}
+
+func (d *debugger) hitBreakpoint() (int, bool) {
+	addr := d.prog.Address()
+	line := d.decode(addr)
+	for id, b := range d.breakpoints {
+		if b.byOp && !line.IsData() && line.Instruction.Opcode == b.opcode {
+			return id, true
+		}
+		if !b.byOp && b.address == addr {
+			return id, true
+		}
+	}
+	return 0, false
+}
+
+func (d *debugger) cont(args []string) error {
+	// always make progress so that continuing from a breakpoint doesn't
+	// immediately stop at the same one
+	for first := true; ; first = false {
+		if !first {
+			if id, hit := d.hitBreakpoint(); hit {
+				d.printf("breakpoint %d: %s\n", id, d.decode(d.prog.Address()))
+				return nil
+			}
+		}
+		if stop, err := d.advance(); stop || err != nil {
+			return err
+		}
+	}
+}
+
+func (d *debugger) addBreakpoint(args []string) error {
+	var b breakpoint
+	switch {
+	case len(args) == 2 && args[0] == "op":
+		b.byOp = true
+		if opcode, ok := intcode.Opcode(strings.ToUpper(args[1])); ok {
+			b.opcode = opcode
+		} else if opcode, err := parseInt(args[1]); err == nil && intcode.Mnemonic(opcode) != "" {
+			b.opcode = opcode
+		} else {
+			return fmt.Errorf("unknown opcode %q", args[1])
+		}
+	case len(args) == 1:
+		addr, err := parseInt(args[0])
+		if err != nil {
+			return err
+		}
+		b.address = addr
+	default:
+		return fmt.Errorf("usage: break <addr> | break op <op>")
+	}
+
+	d.breakpoints[d.nextID] = b
+	d.printf("breakpoint %d: %s\n", d.nextID, b)
+	d.nextID++
+	return nil
+}
+
+func (d *debugger) addWatchpoint(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: watch <addr>")
+	}
+	addr, err := parseInt(args[0])
+	if err != nil {
+		return err
+	}
+	d.watchpoints[d.nextID] = addr
+	d.printf("watchpoint %d: [%d]\n", d.nextID, addr)
+	d.nextID++
+	return nil
+}
+
+func (d *debugger) delete(args []string) error {
+	if len(args) == 1 && args[0] == "all" {
+		d.breakpoints = make(map[int]breakpoint)
+		d.watchpoints = make(map[int]int64)
+		return nil
+	}
+	for _, arg := range args {
+		id, err := strconv.Atoi(arg)
+		if err != nil {
+			return fmt.Errorf("%q is not a breakpoint or watchpoint", arg)
+		}
+		delete(d.breakpoints, id)
+		delete(d.watchpoints, id)
+	}
+	return nil
+}
+
+func (d *debugger) list(args []string) error {
+	ids := make([]int, 0, len(d.breakpoints)+len(d.watchpoints))
+	for id := range d.breakpoints {
+		ids = append(ids, id)
+	}
+	for id := range d.watchpoints {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	for _, id := range ids {
+		if b, ok := d.breakpoints[id]; ok {
+			d.printf("%3d  %s\n", id, b)
+		} else {
+			d.printf("%3d  watch [%d]\n", id, d.watchpoints[id])
+		}
+	}
+	return nil
+}
+
+func (d *debugger) print(args []string) error {
+	if len(args) < 1 || len(args) > 2 {
+		return fmt.Errorf("usage: print <addr> [count]")
+	}
+	addr, err := parseInt(args[0])
+	if err != nil {
+		return err
+	}
+	count := int64(1)
+	if len(args) == 2 {
+		if count, err = parseInt(args[1]); err != nil {
+			return err
+		}
+	}
+
+	mem := d.prog.Memory()
+	for i := int64(0); i < count; i++ {
+		d.printf("[%d] = %d\n", addr+i, mem.Read(addr+i))
+	}
+	return nil
+}
+
+func (d *debugger) set(args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("usage: set <addr> <value>")
+	}
+	addr, err := parseInt(args[0])
+	if err != nil {
+		return err
+	}
+	val, err := parseInt(args[1])
+	if err != nil {
+		return err
+	}
+	d.prog.Memory().Write(addr, val)
+	d.printf("[%d] = %d\n", addr, val)
+	return nil
+}
+
+func (d *debugger) relativeBase(args []string) error {
+	if len(args) == 1 {
+		base, err := parseInt(args[0])
+		if err != nil {
+			return err
+		}
+		d.prog.SetRelativeBase(base)
+	}
+	d.printf("rb = %d\n", d.prog.RelativeBase())
+	return nil
+}
+
+func (d *debugger) queueInput(args []string) error {
+	if len(args) > 0 && args[0] == "-a" {
+		for _, c := range []byte(strings.Join(args[1:], " ") + "\n") {
+			d.input.Push(int64(c))
+		}
+	} else {
+		for _, arg := range args {
+			val, err := parseInt(arg)
+			if err != nil {
+				return err
+			}
+			d.input.Push(val)
+		}
+	}
+	d.printf("%d input(s) queued\n", d.input.Len())
+	return nil
+}
+
+func (d *debugger) showOutput(args []string) error {
+	values := make([]string, len(d.outputs))
+	for i, val := range d.outputs {
+		values[i] = strconv.FormatInt(val, 10)
+	}
+	d.printf("%s\n", strings.Join(values, ","))
+	return nil
+}
+
+func (d *debugger) disassemble(args []string) error {
+	addr, count := d.prog.Address(), int64(10)
+	var err error
+	if len(args) > 0 {
+		if addr, err = parseInt(args[0]); err != nil {
+			return err
+		}
+	}
+	if len(args) > 1 {
+		if count, err = parseInt(args[1]); err != nil {
+			return err
+		}
+	}
+
+	for i := int64(0); i < count; i++ {
+		line := d.decode(addr)
+		marker := " "
+		if addr == d.prog.Address() {
+			marker = ">"
+		}
+		d.printf("%s%s\n", marker, line)
+		addr += int64(len(line.Words))
+	}
+	return nil
+}
+
+func (d *debugger) info(args []string) error {
+	d.printf("state:   %s\n", d.prog.State())
+	d.printf("address: %d\n", d.prog.Address())
+	d.printf("rb:      %d\n", d.prog.RelativeBase())
+	d.printf("inputs:  %d queued\n", d.input.Len())
+	d.printf("outputs: %d\n", len(d.outputs))
+	if err := d.prog.Err(); err != nil {
+		d.printf("fault:   %v\n", err)
+	}
+	d.printf("next:    %s\n", d.decode(d.prog.Address()).Text())
+	return nil
+}
+
+func (d *debugger) snapshot(args []string) error {
+	d.snapshots = append(d.snapshots, snapshot{d.prog.Snapshot(), len(d.outputs)})
+	d.printf("snapshot %d taken at %d\n", len(d.snapshots), d.prog.Address())
+	return nil
+}
+
+func (d *debugger) restore(args []string) error {
+	n := int64(len(d.snapshots))
+	if len(args) == 1 {
+		var err error
+		if n, err = parseInt(args[0]); err != nil {
+			return err
+		}
+	}
+	if n < 1 || n > int64(len(d.snapshots)) {
+		return fmt.Errorf("no snapshot %d", n)
+	}
+	snap := d.snapshots[n-1]
+	d.prog.Restore(snap.Snapshot)
+	d.outputs = d.outputs[:snap.outputs]
+	d.printf("restored snapshot %d at %d\n", n, d.prog.Address())
+	return nil
+}
+
+func (d *debugger) help(args []string) error {
+	for _, cmd := range commands {
+		d.printf("  %-36s %s\n", cmd.usage, cmd.help)
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// session is a transcript of commands and what they should print. Lines
+// starting with the prompt are fed to the debugger.
+const session = `(intdbg) break 6
+breakpoint 1: break at 6
+(intdbg) watch 13
+watchpoint 2: [13]
+(intdbg) input 4 10
+2 input(s) queued
+(intdbg) continue
+watchpoint 2: [13] changed from 0 to 4 at 0
+(intdbg) delete 2
+(intdbg) continue
+breakpoint 1:      6  OUT  [13]
+(intdbg) snapshot
+snapshot 1 taken at 6
+(intdbg) continue
+output: 5
+breakpoint 1:      6  OUT  [13]
+(intdbg) output
+5
+(intdbg) restore
+restored snapshot 1 at 6
+(intdbg) print 13
+[13] = 5
+(intdbg) output
+
+(intdbg) info
+state:   Running
+address: 6
+rb:      0
+inputs:  1 queued
+outputs: 0
+next:    OUT  [13]
+(intdbg) delete all
+(intdbg) step
+output: 5
+     8  JT   #1, #0
+(intdbg) continue
+output: 11
+waiting for input at 0
+(intdbg) output
+5,11
+(intdbg) restore 2
+no snapshot 2
+(intdbg) bogus
+unknown command "bogus", try help
+`
+
+func TestSession(t *testing.T) {
+	// adds one to each input and outputs it
+	program := []int64{3, 13, 1001, 13, 1, 13, 4, 13, 1105, 1, 0, 99, 0, 0}
+
+	var out strings.Builder
+	d := newDebugger(program, &out)
+	for _, line := range strings.Split(session, "\n") {
+		cmd, ok := strings.CutPrefix(line, "(intdbg) ")
+		if !ok {
+			continue
+		}
+		fmt.Fprintln(&out, line)
+		if err := d.exec(cmd); err != nil {
+			fmt.Fprintln(&out, err)
+		}
+	}
+
+	if out.String() != session {
+		t.Errorf("expected:\n%s\nbut got:\n%s", session, out.String())
+	}
+}
@@ -0,0 +1,55 @@
+// Command intdbg is an interactive debugger for Intcode programs.
+//
+//	$ go run ./cmd/intdbg inputs/13.txt
+//
+// Type help at the prompt for the list of commands. An empty line repeats the
+// previous command, which makes stepping through a program easier.
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/dcoxall/advent-of-code-2019/intcode"
+)
+
+func main() {
+	if len(os.Args) != 2 {
+		fmt.Fprintln(os.Stderr, "usage: intdbg <program>")
+		os.Exit(2)
+	}
+
+	f, err := os.Open(os.Args[1])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	program, err := intcode.Parse(f)
+	f.Close()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	d := newDebugger(program, os.Stdout)
+	d.info(nil)
+
+	scanner := bufio.NewScanner(os.Stdin)
+	var last string
+	for fmt.Print("(intdbg) "); scanner.Scan(); fmt.Print("(intdbg) ") {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			line = last
+		}
+		if line == "quit" || line == "q" {
+			return
+		}
+		if err := d.exec(line); err != nil {
+			fmt.Println(err)
+		}
+		last = line
+	}
+	fmt.Println()
+}
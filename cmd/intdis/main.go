@@ -0,0 +1,46 @@
+// Command intdis prints a readable listing of an Intcode program.
+//
+//	$ go run ./cmd/intdis [-follow] inputs/09.txt
+//
+// The program is read from standard input when no file is given.
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/dcoxall/advent-of-code-2019/intcode"
+	"github.com/dcoxall/advent-of-code-2019/intcode/asm"
+)
+
+func main() {
+	follow := flag.Bool("follow", false, "only decode instructions reachable by following jumps from address 0")
+	flag.Parse()
+
+	var rdr io.Reader = os.Stdin
+	if path := flag.Arg(0); path != "" {
+		f, err := os.Open(path)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		rdr = f
+	}
+
+	program, err := intcode.Parse(rdr)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	w := bufio.NewWriter(os.Stdout)
+	defer w.Flush()
+
+	for _, line := range asm.Disassemble(program, *follow) {
+		fmt.Fprintln(w, line)
+	}
+}
@@ -0,0 +1,3 @@
+module github.com/dcoxall/advent-of-code-2019
+
+go 1.21
@@ -0,0 +1,86 @@
+// Package grid has the points and directions shared by the puzzles that
+// walk around a 2D grid.
+//
+// Y increases downwards, as it does when a grid is printed line by line, so
+// Up moves towards smaller Y.
+package grid
+
+import "fmt"
+
+type Point struct {
+	X int
+	Y int
+}
+
+func (p Point) Add(q Point) Point {
+	return Point{p.X + q.X, p.Y + q.Y}
+}
+
+// Move returns the point one step away in the direction.
+func (p Point) Move(d Direction) Point {
+	return p.Add(d.Delta())
+}
+
+// Neighbours returns the four points next to p, in the same order as
+// Directions.
+func (p Point) Neighbours() []Point {
+	neighbours := make([]Point, len(Directions))
+	for i, d := range Directions {
+		neighbours[i] = p.Move(d)
+	}
+	return neighbours
+}
+
+// Direction is one of the four ways to move on the grid.
+type Direction int
+
+const (
+	Up Direction = iota
+	Right
+	Down
+	Left
+)
+
+// Directions lists every direction clockwise from Up.
+var Directions = [4]Direction{Up, Right, Down, Left}
+
+func (d Direction) TurnLeft() Direction {
+	return (d + 3) % 4
+}
+
+func (d Direction) TurnRight() Direction {
+	return (d + 1) % 4
+}
+
+func (d Direction) Reverse() Direction {
+	return (d + 2) % 4
+}
+
+// Delta is the change in position from moving one step in the direction.
+func (d Direction) Delta() Point {
+	switch d {
+	case Up:
+		return Point{0, -1}
+	case Right:
+		return Point{1, 0}
+	case Down:
+		return Point{0, 1}
+	case Left:
+		return Point{-1, 0}
+	}
+	return Point{}
+}
+
+func (d Direction) String() string {
+	switch d {
+	case Up:
+		return "Up"
+	case Right:
+		return "Right"
+	case Down:
+		return "Down"
+	case Left:
+		return "Left"
+	}
+	return fmt.Sprintf("Direction(%d)", int(d))
+}
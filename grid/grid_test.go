@@ -0,0 +1,92 @@
+package grid_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/dcoxall/advent-of-code-2019/canvas"
+	"github.com/dcoxall/advent-of-code-2019/grid"
+)
+
+func TestTurns(t *testing.T) {
+	for _, d := range grid.Directions {
+		left, right := d, d
+		for i := 0; i < 4; i++ {
+			left, right = left.TurnLeft(), right.TurnRight()
+			if i < 3 && (left == d || right == d) {
+				t.Errorf("%s: back to the start after %d turns", d, i+1)
+			}
+		}
+		if left != d || right != d {
+			t.Errorf("%s: expected four turns to come back round but got %s and %s", d, left, right)
+		}
+		if d.TurnLeft().TurnLeft() != d.Reverse() || d.Reverse().Reverse() != d {
+			t.Errorf("%s: expected the reverse to be two turns away", d)
+		}
+	}
+
+	if grid.Up.TurnRight() != grid.Right || grid.Up.TurnLeft() != grid.Left {
+		t.Errorf("expected right of Up to be Right and left to be Left")
+	}
+}
+
+func TestDelta(t *testing.T) {
+	deltas := map[grid.Direction]grid.Point{
+		grid.Up:    {X: 0, Y: -1},
+		grid.Right: {X: 1, Y: 0},
+		grid.Down:  {X: 0, Y: 1},
+		grid.Left:  {X: -1, Y: 0},
+	}
+	for d, expected := range deltas {
+		if delta := d.Delta(); delta != expected {
+			t.Errorf("%s: expected %v but got %v", d, expected, delta)
+		}
+		if back := d.Delta().Add(d.Reverse().Delta()); back != (grid.Point{}) {
+			t.Errorf("%s: expected reversing to cancel out but got %v", d, back)
+		}
+	}
+}
+
+func TestNeighbours(t *testing.T) {
+	p := grid.Point{X: 2, Y: -3}
+	expected := []grid.Point{{X: 2, Y: -4}, {X: 3, Y: -3}, {X: 2, Y: -2}, {X: 1, Y: -3}}
+	if neighbours := p.Neighbours(); !reflect.DeepEqual(neighbours, expected) {
+		t.Errorf("expected %v but got %v", expected, neighbours)
+	}
+	for i, d := range grid.Directions {
+		if p.Move(d) != expected[i] {
+			t.Errorf("%s: expected %v but got %v", d, expected[i], p.Move(d))
+		}
+	}
+}
+
+func TestWalk(t *testing.T) {
+	// a small map walked out by turning and moving, then drawn
+	visited := make(map[grid.Point]bool)
+	p, d := grid.Point{}, grid.Up
+	for _, turn := range "RRLLLR" {
+		if turn == 'L' {
+			d = d.TurnLeft()
+		} else {
+			d = d.TurnRight()
+		}
+		visited[p] = true
+		p = p.Move(d)
+	}
+	visited[p] = true
+
+	c := canvas.New(visited, canvas.Palette(map[bool]rune{true: '#'}, '?'))
+	c.Mark(grid.Point{}, 'S')
+	bounds, ok := c.Bounds()
+	expected := canvas.Rect{Min: grid.Point{X: 0, Y: -1}, Max: grid.Point{X: 2, Y: 1}}
+	if !ok || bounds != expected {
+		t.Errorf("expected bounds %v but got %v", expected, bounds)
+	}
+
+	drawn := " # \n" +
+		"S##\n" +
+		" ##\n"
+	if out := c.String(); out != drawn {
+		t.Errorf("expected\n%s\nbut got\n%s", drawn, out)
+	}
+}
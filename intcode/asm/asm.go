@@ -0,0 +1,251 @@
+package asm
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/dcoxall/advent-of-code-2019/intcode"
+)
+
+// Error reports a problem with a line of the source.
+type Error struct {
+	Line int
+	Msg  string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("asm: line %d: %s", e.Line, e.Msg)
+}
+
+// statement is an instruction or data directive waiting for its labels to be
+// resolved.
+type statement struct {
+	line     int
+	opcode   int64
+	data     bool
+	modes    [3]int64
+	operands []string
+}
+
+func (s statement) size() int64 {
+	if s.data {
+		return int64(len(s.operands))
+	}
+	return int64(len(s.operands)) + 1
+}
+
+// Assemble reads assembly source and returns the program it describes.
+//
+// As well as everything written by the disassembler the source can contain:
+//
+//	; comments running to the end of the line
+//	loop:                 labels, usable anywhere a number is
+//	db 1, 2, label+1      data, the same as DATA but with several values
+//
+// A number at the start of a line is taken to be the address printed by the
+// disassembler and is ignored.
+func Assemble(r io.Reader) ([]int64, error) {
+	labels := make(map[string]int64)
+	statements := make([]statement, 0)
+	var addr int64
+
+	scanner := bufio.NewScanner(r)
+	for num := 1; scanner.Scan(); num++ {
+		text := scanner.Text()
+		if i := strings.IndexByte(text, ';'); i >= 0 {
+			text = text[:i]
+		}
+		fields := strings.Fields(text)
+
+		// skip any address printed by the disassembler
+		if len(fields) > 1 && isNumber(fields[0]) {
+			text = strings.TrimSpace(text)[len(fields[0]):]
+			fields = fields[1:]
+		}
+
+		// peel off any labels
+		text = strings.TrimSpace(text)
+		for len(fields) > 0 && strings.HasSuffix(fields[0], ":") {
+			name := strings.TrimSuffix(fields[0], ":")
+			if !isLabel(name) {
+				return nil, &Error{num, fmt.Sprintf("invalid label %q", name)}
+			}
+			if _, exists := labels[name]; exists {
+				return nil, &Error{num, fmt.Sprintf("label %q already defined", name)}
+			}
+			labels[name] = addr
+			text = strings.TrimSpace(text[len(fields[0]):])
+			fields = fields[1:]
+		}
+
+		if len(fields) == 0 {
+			continue
+		}
+
+		stmt, err := parseStatement(num, fields[0], strings.TrimSpace(text[len(fields[0]):]))
+		if err != nil {
+			return nil, err
+		}
+		statements = append(statements, stmt)
+		addr += stmt.size()
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	program := make([]int64, 0, addr)
+	for _, stmt := range statements {
+		if !stmt.data {
+			inst := intcode.Instruction{Opcode: stmt.opcode, Modes: stmt.modes}
+			program = append(program, inst.Encode())
+		}
+		for _, operand := range stmt.operands {
+			val, err := evaluate(operand, labels)
+			if err != nil {
+				return nil, &Error{stmt.line, err.Error()}
+			}
+			program = append(program, val)
+		}
+	}
+	return program, nil
+}
+
+func parseStatement(line int, mnemonic, rest string) (statement, error) {
+	stmt := statement{line: line, operands: make([]string, 0)}
+	if rest != "" {
+		for _, operand := range strings.Split(rest, ",") {
+			stmt.operands = append(stmt.operands, strings.TrimSpace(operand))
+		}
+	}
+
+	mnemonic = strings.ToUpper(mnemonic)
+	if mnemonic == "DB" || mnemonic == "DATA" {
+		if len(stmt.operands) == 0 {
+			return stmt, &Error{line, mnemonic + " needs at least one value"}
+		}
+		stmt.data = true
+		return stmt, nil
+	}
+
+	opcode, ok := intcode.Opcode(mnemonic)
+	if !ok {
+		return stmt, &Error{line, fmt.Sprintf("unknown mnemonic %q", mnemonic)}
+	}
+	stmt.opcode = opcode
+
+	inst := intcode.Instruction{Opcode: opcode}
+	if count := inst.Parameters(); int64(len(stmt.operands)) != count {
+		return stmt, &Error{line, fmt.Sprintf("%s takes %d operands but has %d", mnemonic, count, len(stmt.operands))}
+	}
+
+	// strip the mode markers leaving just the value
+	target, writing := inst.Writes()
+	for n, operand := range stmt.operands {
+		lower := strings.ToLower(operand)
+		switch {
+		case strings.HasPrefix(operand, "[") && strings.HasSuffix(operand, "]"):
+			stmt.modes[n] = intcode.ModePosition
+			operand = operand[1 : len(operand)-1]
+		case strings.HasPrefix(operand, "#"):
+			if writing && int64(n) == target {
+				return stmt, &Error{line, fmt.Sprintf("%s can't write to immediate operand %q", mnemonic, operand)}
+			}
+			stmt.modes[n] = intcode.ModeImmediate
+			operand = operand[1:]
+		case lower == "rb" || strings.HasPrefix(lower, "rb+") || strings.HasPrefix(lower, "rb-"):
+			stmt.modes[n] = intcode.ModeRelative
+			operand = "0" + operand[2:]
+		default:
+			return stmt, &Error{line, fmt.Sprintf("operand %q has no mode, use [x], #x or rb+x", operand)}
+		}
+		stmt.operands[n] = strings.TrimSpace(operand)
+	}
+
+	return stmt, nil
+}
+
+// evaluate works out the value of a sum of numbers and labels such as
+// "loop+2" or "-5".
+func evaluate(expr string, labels map[string]int64) (int64, error) {
+	var total int64
+	sign := int64(1)
+	term := ""
+
+	add := func() error {
+		term = strings.TrimSpace(term)
+		switch {
+		case term == "":
+			return fmt.Errorf("missing value in %q", expr)
+		case isNumber(term):
+			val, err := strconv.ParseInt(term, 10, 64)
+			if err != nil {
+				return err
+			}
+			total += sign * val
+		case isLabel(term):
+			val, ok := labels[term]
+			if !ok {
+				return fmt.Errorf("undefined label %q", term)
+			}
+			total += sign * val
+		default:
+			return fmt.Errorf("invalid value %q", term)
+		}
+		return nil
+	}
+
+	for i, c := range expr {
+		if c != '+' && c != '-' {
+			term += string(c)
+			continue
+		}
+		if i == 0 || strings.TrimSpace(expr[:i]) == "" {
+			// a leading sign belongs to the first value
+			if c == '-' {
+				sign = -sign
+			}
+			continue
+		}
+		if err := add(); err != nil {
+			return 0, err
+		}
+		term = ""
+		sign = 1
+		if c == '-' {
+			sign = -1
+		}
+	}
+	if err := add(); err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
+func isNumber(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if !unicode.IsDigit(c) {
+			return false
+		}
+	}
+	return true
+}
+
+func isLabel(s string) bool {
+	if s == "" || strings.EqualFold(s, "rb") {
+		return false
+	}
+	for i, c := range s {
+		if c != '_' && c != '.' && !unicode.IsLetter(c) && (i == 0 || !unicode.IsDigit(c)) {
+			return false
+		}
+	}
+	return true
+}
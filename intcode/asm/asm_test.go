@@ -0,0 +1,86 @@
+package asm
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/dcoxall/advent-of-code-2019/intcode"
+)
+
+func TestAssemble(t *testing.T) {
+	source := `
+; count down from 3, printing each number
+        ARB  #stack
+start:  OUT  [count]
+        ADD  [count], #-1, [count]
+        JT   [count], #start
+        ADD  #0, #7, rb-1 ; relative operands
+        HLT
+count:  db 3
+stack:  DATA 0
+`
+	expected := []int64{109, 17, 4, 16, 1001, 16, -1, 16, 1005, 16, 2, 21101, 0, 7, -1, 99, 3, 0}
+	program, err := Assemble(strings.NewReader(source))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(program, expected) {
+		t.Fatalf("expected %v but got %v", expected, program)
+	}
+}
+
+func TestAssembleErrors(t *testing.T) {
+	sources := map[string]string{
+		"ADD [1], [2]":     "line 1: ADD takes 3 operands but has 2",
+		"JMP #0, #0":       `line 1: unknown mnemonic "JMP"`,
+		"OUT [nowhere]":    `line 1: undefined label "nowhere"`,
+		"OUT 4":            `line 1: operand "4" has no mode, use [x], #x or rb+x`,
+		"ADD #1, #1, #3":   `line 1: ADD can't write to immediate operand "#3"`,
+		"IN #5":            `line 1: IN can't write to immediate operand "#5"`,
+		"a: HLT\na: HLT":   `line 2: label "a" already defined`,
+		"IN rb+1+":         `line 1: missing value in "0+1+"`,
+		"db":               "line 1: DB needs at least one value",
+		"3bad: db 1, 2, 3": `line 1: invalid label "3bad"`,
+	}
+
+	for source, msg := range sources {
+		_, err := Assemble(strings.NewReader(source))
+		if err == nil || err.Error() != "asm: "+msg {
+			t.Errorf("%q: expected error %q but got %v", source, msg, err)
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	paths, _ := filepath.Glob("../../inputs/*.txt")
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		program, err := intcode.Parse(f)
+		f.Close()
+		if err != nil {
+			// not every input is an Intcode program
+			continue
+		}
+
+		for _, follow := range []bool{false, true} {
+			var sb strings.Builder
+			for _, line := range Disassemble(program, follow) {
+				sb.WriteString(line.String() + "\n")
+			}
+
+			assembled, err := Assemble(strings.NewReader(sb.String()))
+			if err != nil {
+				t.Fatalf("%s: %v", path, err)
+			}
+			if !reflect.DeepEqual(assembled, program) {
+				t.Errorf("%s: disassembly (follow=%v) did not assemble to the same program", path, follow)
+			}
+		}
+	}
+}
@@ -0,0 +1,137 @@
+// Package asm translates Intcode programs to and from a readable assembly
+// language.
+//
+// Each line holds a mnemonic followed by its operands. Operands are written
+// with a marker for their parameter mode:
+//
+//	[x]   position mode, the value at address x
+//	#x    immediate mode, the value x itself
+//	rb+x  relative mode, the value at address x from the relative base
+//
+// Words that don't decode to an instruction are written as DATA.
+package asm
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dcoxall/advent-of-code-2019/intcode"
+)
+
+// Line is either a decoded instruction or a single word of data.
+type Line struct {
+	Address     int64
+	Words       []int64
+	Instruction *intcode.Instruction
+}
+
+func (l Line) IsData() bool {
+	return l.Instruction == nil
+}
+
+// Text is the assembly for the line without its address.
+func (l Line) Text() string {
+	if l.IsData() {
+		return fmt.Sprintf("DATA %d", l.Words[0])
+	}
+
+	operands := make([]string, 0, len(l.Words)-1)
+	for n, word := range l.Words[1:] {
+		operands = append(operands, operand(l.Instruction.Modes[n], word))
+	}
+	return strings.TrimSpace(fmt.Sprintf("%-4s %s", l.Instruction.Mnemonic(), strings.Join(operands, ", ")))
+}
+
+func (l Line) String() string {
+	return fmt.Sprintf("%6d  %s", l.Address, l.Text())
+}
+
+func operand(mode, val int64) string {
+	switch mode {
+	case intcode.ModeImmediate:
+		return fmt.Sprintf("#%d", val)
+	case intcode.ModeRelative:
+		if val < 0 {
+			return fmt.Sprintf("rb%d", val)
+		}
+		return fmt.Sprintf("rb+%d", val)
+	}
+	return fmt.Sprintf("[%d]", val)
+}
+
+// DecodeAt decodes the instruction at addr, falling back to data when the
+// word isn't an instruction or its parameters run off the end of the program.
+func DecodeAt(program []int64, addr int64) Line {
+	inst, err := intcode.Decode(program[addr])
+	if err != nil || addr+inst.Length() > int64(len(program)) {
+		return Line{Address: addr, Words: program[addr : addr+1]}
+	}
+	inst.Address = addr
+	return Line{Address: addr, Words: program[addr : addr+inst.Length()], Instruction: &inst}
+}
+
+// Disassemble decodes the whole program. Every word that decodes is treated
+// as an instruction unless follow is set, in which case only instructions
+// reachable from address 0 are. Jumps are followed when their target is an
+// immediate value, anything else can only be known when running, so code
+// that is only reached by returning from a call is shown as data.
+func Disassemble(program []int64, follow bool) []Line {
+	var code map[int64]bool
+	if follow {
+		code = reachable(program)
+	}
+
+	lines := make([]Line, 0, len(program))
+	for addr := int64(0); addr < int64(len(program)); {
+		line := DecodeAt(program, addr)
+		if follow && !code[addr] {
+			line = Line{Address: addr, Words: program[addr : addr+1]}
+		}
+		lines = append(lines, line)
+		addr += int64(len(line.Words))
+	}
+	return lines
+}
+
+// reachable walks every path through the program from address 0 and returns
+// the addresses instructions were found at.
+func reachable(program []int64) map[int64]bool {
+	code := make(map[int64]bool)
+	pending := []int64{0}
+
+	for len(pending) > 0 {
+		addr := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+
+		for addr >= 0 && addr < int64(len(program)) && !code[addr] {
+			line := DecodeAt(program, addr)
+			if line.IsData() {
+				break
+			}
+			code[addr] = true
+
+			inst := line.Instruction
+			if inst.Opcode == intcode.OpJmpTrue || inst.Opcode == intcode.OpJmpFalse {
+				// a constant condition only ever goes one way
+				taken, skipped := true, true
+				if inst.Modes[0] == intcode.ModeImmediate {
+					taken = (line.Words[1] != 0) == (inst.Opcode == intcode.OpJmpTrue)
+					skipped = !taken
+				}
+				if taken && inst.Modes[1] == intcode.ModeImmediate {
+					pending = append(pending, line.Words[2])
+				}
+				if !skipped {
+					break
+				}
+			}
+			if inst.Opcode == intcode.OpHalt {
+				break
+			}
+
+			addr += inst.Length()
+		}
+	}
+
+	return code
+}
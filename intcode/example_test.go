@@ -0,0 +1,76 @@
+package intcode_test
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dcoxall/advent-of-code-2019/intcode"
+)
+
+// outputs runs the program to completion and returns everything it output.
+func outputs(program []int64, inputs ...int64) []int64 {
+	prog := intcode.New(program)
+	prog.ResumeWith(inputs...)
+
+	var values []int64
+	for ok, val := prog.ReadOutput(); ok; ok, val = prog.ReadOutput() {
+		values = append(values, val)
+	}
+	return values
+}
+
+func join(values []int64) string {
+	parts := make([]string, len(values))
+	for i, val := range values {
+		parts[i] = fmt.Sprint(val)
+	}
+	return strings.Join(parts, ",")
+}
+
+// The Day 9 program that outputs a copy of itself.
+func Example_quine() {
+	quine := []int64{109, 1, 204, -1, 1001, 100, 1, 100, 1008, 100, 16, 101, 1006, 101, 0, 99}
+	fmt.Println(join(outputs(quine)))
+	// Output: 109,1,204,-1,1001,100,1,100,1008,100,16,101,1006,101,0,99
+}
+
+func Example_largeNumbers() {
+	fmt.Println(outputs([]int64{104, 1125899906842624, 99}))
+	fmt.Println(outputs([]int64{1102, 34915192, 34915192, 7, 4, 7, 99, 0}))
+	// Output:
+	// [1125899906842624]
+	// [1219070632396864]
+}
+
+// The Day 5 programs comparing their input to 8.
+func Example_compare() {
+	programs := []struct {
+		name    string
+		program []int64
+	}{
+		{"position ==", []int64{3, 9, 8, 9, 10, 9, 4, 9, 99, -1, 8}},
+		{"position < ", []int64{3, 9, 7, 9, 10, 9, 4, 9, 99, -1, 8}},
+		{"immediate ==", []int64{3, 3, 1108, -1, 8, 3, 4, 3, 99}},
+		{"immediate < ", []int64{3, 3, 1107, -1, 8, 3, 4, 3, 99}},
+	}
+	for _, p := range programs {
+		fmt.Println(p.name, outputs(p.program, 7), outputs(p.program, 8), outputs(p.program, 9))
+	}
+	// Output:
+	// position == [0] [1] [0]
+	// position <  [1] [0] [0]
+	// immediate == [0] [1] [0]
+	// immediate <  [1] [0] [0]
+}
+
+// The larger Day 5 program which outputs 999, 1000 or 1001 depending on
+// whether its input is below, equal to or above 8.
+func Example_jumps() {
+	program := []int64{
+		3, 21, 1008, 21, 8, 20, 1005, 20, 22, 107, 8, 21, 20, 1006, 20, 31,
+		1106, 0, 36, 98, 0, 0, 1002, 21, 125, 20, 4, 20, 1105, 1, 46, 104,
+		999, 1105, 1, 46, 1101, 1000, 1, 20, 4, 20, 1105, 1, 46, 98, 99,
+	}
+	fmt.Println(outputs(program, 7), outputs(program, 8), outputs(program, 9))
+	// Output: [999] [1000] [1001]
+}
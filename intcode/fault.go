@@ -0,0 +1,47 @@
+package intcode
+
+import "fmt"
+
+// Fault is returned when the machine comes across something it can't
+// execute. It captures enough of the machine's state to find the problem in
+// the program.
+type Fault struct {
+	Address      int64
+	Word         int64
+	Opcode       int64
+	RelativeBase int64
+	Reason       string
+}
+
+func (f *Fault) Error() string {
+	return fmt.Sprintf(
+		"intcode: %s at address %d (instruction %d, opcode %d, relative base %d)",
+		f.Reason, f.Address, f.Word, f.Opcode, f.RelativeBase,
+	)
+}
+
+// BudgetError is returned when a machine executes more instructions than its
+// budget without doing any I/O.
+type BudgetError struct {
+	Address int64
+	Budget  int64
+}
+
+func (e *BudgetError) Error() string {
+	return fmt.Sprintf("intcode: no I/O in %d instructions, stopped at address %d", e.Budget, e.Address)
+}
+
+// validate checks that an instruction can be executed before anything is
+// changed so a fault always leaves the machine as it was.
+func (prog *Intcode) validate(word int64, inst *Instruction) error {
+	if reason := check(word, *inst); reason != "" {
+		return &Fault{
+			Address:      inst.Address,
+			Word:         word,
+			Opcode:       inst.Opcode,
+			RelativeBase: prog.relativeBase,
+			Reason:       reason,
+		}
+	}
+	return nil
+}
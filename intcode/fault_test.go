@@ -0,0 +1,63 @@
+package intcode
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestFault(t *testing.T) {
+	tests := []struct {
+		name    string
+		program []int64
+		fault   Fault
+	}{
+		{"UnknownOpcode", []int64{109, 7, 42}, Fault{
+			Address: 2, Word: 42, Opcode: 42, RelativeBase: 7, Reason: "unknown opcode",
+		}},
+		{"InvalidMode", []int64{109, -3, 301, 0, 0, 0}, Fault{
+			Address: 2, Word: 301, Opcode: 1, RelativeBase: -3, Reason: "invalid mode 3 for parameter 1",
+		}},
+		{"TooManyDigits", []int64{300001, 0, 0, 0}, Fault{
+			Address: 0, Word: 300001, Opcode: 1, Reason: "too many digits",
+		}},
+		{"ImmediateWrite", []int64{11101, 1, 1, 3, 99}, Fault{
+			Address: 0, Word: 11101, Opcode: 1, Reason: "immediate mode for write parameter 3",
+		}},
+		{"MissingParameter", []int64{104, 1, 1104, 0}, Fault{
+			Address: 2, Word: 1104, Opcode: 4, Reason: "mode 1 for missing parameter 2",
+		}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			prog := New(test.program)
+			state, inst, err := prog.Run(context.Background())
+			if state != Faulted {
+				t.Fatalf("expected Faulted but got %s", state)
+			}
+
+			var fault *Fault
+			if !errors.As(err, &fault) {
+				t.Fatalf("expected a *Fault but got %v", err)
+			}
+			if *fault != test.fault {
+				t.Errorf("expected %+v but got %+v", test.fault, *fault)
+			}
+			if inst.Address != test.fault.Address || prog.Address() != test.fault.Address {
+				t.Errorf("expected to stop at %d but got %d", test.fault.Address, prog.Address())
+			}
+			if prog.Err() != err {
+				t.Errorf("expected Err to return the fault but got %v", prog.Err())
+			}
+
+			// the machine stays faulted
+			if state, _, again := prog.Step(); state != Faulted || again != err {
+				t.Errorf("expected the same fault again but got %s, %v", state, again)
+			}
+			if _, err := Decode(test.fault.Word); err == nil {
+				t.Errorf("expected Decode to reject %d", test.fault.Word)
+			}
+		})
+	}
+}
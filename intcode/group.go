@@ -0,0 +1,377 @@
+package intcode
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultGrace is how long a Group waits with every machine blocked before it
+// decides they are deadlocked, when it can't tell from the channels between
+// them.
+const DefaultGrace = 100 * time.Millisecond
+
+// Group runs machines in their own goroutines and watches their I/O. When
+// they all end up blocked, waiting on each other or on a value nobody is
+// going to send, the group gives up on them and reports where each one is
+// stuck rather than hanging forever.
+//
+// The machines share a context which is cancelled as soon as one of them
+// fails or they become deadlocked, so that the rest are stopped too. Only
+// machines blocked on a ContextInput or ContextOutput, such as the channel
+// adapters, can be stopped while they wait. A machine blocked on anything
+// else, such as an InputFunc that never returns, can't be, and Wait waits for
+// it forever.
+//
+// Machines connected to each other by channels are watched exactly. The
+// group counts the values passed over each channel so it knows when one is
+// still being handed over, and reports a deadlock as soon as every machine
+// is waiting on a channel that only another blocked machine could use. This
+// relies on those channels only being used by the machines in the group.
+//
+// Anything else is invisible to the group, such as main feeding an input
+// channel or a machine writing to an OutputFunc. When every machine is
+// blocked and any of them is waiting on something like that, the group falls
+// back to waiting for nothing to change for Grace before calling it a
+// deadlock. A driver slower than that has its machines stopped, so raise
+// Grace for a slow driver or only start waiting once it has sent everything.
+//
+// A machine that stops because it needs an input it will never get, from an
+// empty Queue or a closed channel, is reported as stuck too.
+//
+// Watching starts when Done or Wait is first called, so every machine should
+// have been added by then. Go panics if the group has already finished.
+type Group struct {
+	// Grace is how long every machine must stay blocked when the group can't
+	// tell whether they are deadlocked. It should be set before calling Done
+	// or Wait.
+	Grace time.Duration
+
+	ctx     context.Context
+	cancel  context.CancelFunc
+	running sync.WaitGroup
+
+	mu       sync.Mutex
+	members  []*member
+	channels map[uintptr]*channel
+	version  int64
+	changed  chan struct{}
+	done     chan struct{}
+	err      error
+	first    error
+	finished bool
+	once     sync.Once
+}
+
+type member struct {
+	group   *Group
+	name    string
+	waiting State
+	address int64
+	stopped bool
+	// stranded is set when the machine stopped needing an input
+	stranded bool
+
+	// the channels the machine reads from and writes to, when it uses them
+	in, out *channel
+}
+
+// channel keeps track of a channel used by the machines in a group.
+type channel struct {
+	readers, writers int
+	// values the machines have finished passing over the channel, along
+	// with any that were already buffered when it was first seen
+	reads, writes int64
+	len, cap      func() int
+}
+
+// internal reports whether both ends of the channel are used by machines in
+// the group.
+func (c *channel) internal() bool {
+	return c.readers > 0 && c.writers > 0
+}
+
+// Blocked describes a machine stuck on I/O.
+type Blocked struct {
+	Name    string
+	Address int64
+	// Output is true if the machine is trying to write a value rather than
+	// read one.
+	Output bool
+}
+
+func (b Blocked) String() string {
+	if b.Output {
+		return fmt.Sprintf("%s at %d (output)", b.Name, b.Address)
+	}
+	return fmt.Sprintf("%s at %d (input)", b.Name, b.Address)
+}
+
+// DeadlockError is returned when every machine in a group is blocked on I/O,
+// or has stopped for want of an input.
+type DeadlockError struct {
+	Machines []Blocked
+}
+
+func (e *DeadlockError) Error() string {
+	stuck := make([]string, len(e.Machines))
+	for i, b := range e.Machines {
+		stuck[i] = b.String()
+	}
+	return fmt.Sprintf("intcode: deadlock, blocked on I/O: %s", strings.Join(stuck, ", "))
+}
+
+// NewGroup creates a group whose machines are stopped when ctx is done.
+func NewGroup(ctx context.Context) *Group {
+	ctx, cancel := context.WithCancel(ctx)
+	return &Group{
+		Grace:    DefaultGrace,
+		ctx:      ctx,
+		cancel:   cancel,
+		changed:  make(chan struct{}, 1),
+		channels: make(map[uintptr]*channel),
+		done:     make(chan struct{}),
+	}
+}
+
+// Go runs the machine in a new goroutine as part of the group. The name is
+// used to identify it in errors.
+func (g *Group) Go(name string, prog *Intcode) {
+	m := &member{group: g, name: name, waiting: Running}
+
+	g.mu.Lock()
+	if g.finished {
+		g.mu.Unlock()
+		panic("intcode: Group.Go called after the group finished")
+	}
+	if in, ok := prog.input.(ChannelInput); ok && in != nil {
+		m.in = g.channel(reflect.ValueOf(in).Pointer(), func() int { return len(in) }, func() int { return cap(in) })
+		m.in.readers++
+	}
+	if out, ok := prog.output.(ChannelOutput); ok && out != nil {
+		m.out = g.channel(reflect.ValueOf(out).Pointer(), func() int { return len(out) }, func() int { return cap(out) })
+		m.out.writers++
+	}
+	g.members = append(g.members, m)
+	g.version++
+	g.running.Add(1)
+	g.mu.Unlock()
+	prog.member = m
+	g.notify()
+
+	go func() {
+		defer g.running.Done()
+		state, _, err := prog.Run(g.ctx)
+		g.mu.Lock()
+		m.stopped = true
+		if err == nil && state == NeedsInput {
+			m.stranded = true
+			m.waiting = NeedsInput
+			m.address = prog.address
+		}
+		if err != nil && g.first == nil {
+			g.first = fmt.Errorf("intcode: machine %s: %w", name, err)
+		}
+		g.version++
+		g.mu.Unlock()
+		if err != nil {
+			g.cancel()
+		}
+		g.notify()
+	}()
+}
+
+// channel returns the tracking for the channel with the given identity,
+// starting it off with whatever is already buffered.
+func (g *Group) channel(id uintptr, length, capacity func() int) *channel {
+	c, ok := g.channels[id]
+	if !ok {
+		c = &channel{writes: int64(length()), len: length, cap: capacity}
+		g.channels[id] = c
+	}
+	return c
+}
+
+// Done is closed once every machine has stopped, or they are deadlocked.
+func (g *Group) Done() <-chan struct{} {
+	g.once.Do(func() { go g.monitor() })
+	return g.done
+}
+
+// Wait blocks until every machine has stopped and returns the first error
+// any of them ran into. If instead they become deadlocked a *DeadlockError is
+// returned once they have been stopped.
+func (g *Group) Wait() error {
+	<-g.Done()
+	g.running.Wait()
+	return g.err
+}
+
+// blocked marks the machine as waiting on I/O. It does nothing unless the
+// machine belongs to a group.
+func (prog *Intcode) blocked(on State) {
+	if m := prog.member; m != nil {
+		m.group.mu.Lock()
+		m.waiting = on
+		m.address = prog.address
+		m.group.version++
+		m.group.mu.Unlock()
+		m.group.notify()
+	}
+}
+
+// unblocked marks the machine as running again once it is done waiting,
+// counting the value if one was passed over a channel.
+func (prog *Intcode) unblocked(passed bool) {
+	if m := prog.member; m != nil {
+		m.group.mu.Lock()
+		switch {
+		case !passed:
+		case m.waiting == NeedsInput && m.in != nil:
+			m.in.reads++
+		case m.waiting == HasOutput && m.out != nil:
+			m.out.writes++
+		}
+		m.waiting = Running
+		m.group.version++
+		m.group.mu.Unlock()
+		m.group.notify()
+	}
+}
+
+func (g *Group) notify() {
+	select {
+	case g.changed <- struct{}{}:
+	default:
+	}
+}
+
+// monitor watches for the machines to all stop or all block, and finishes
+// the group when they do.
+func (g *Group) monitor() {
+	for {
+		g.mu.Lock()
+		version := g.version
+		running, blocked, stranded := 0, 0, false
+		for _, m := range g.members {
+			stranded = stranded || m.stranded
+			if m.stopped {
+				continue
+			}
+			running++
+			if m.waiting != Running {
+				blocked++
+			}
+		}
+		visible, stuck := false, false
+		if running > 0 && blocked == running {
+			visible, stuck = g.stuck()
+		}
+		g.mu.Unlock()
+
+		switch {
+		case running == 0 && stranded:
+			g.finish(g.deadlock())
+			return
+		case running == 0:
+			g.finish(nil)
+			return
+		case stuck:
+			g.finish(g.deadlock())
+			return
+		case blocked < running || visible:
+			// either some are still running, or a value is on its way
+			// between two of them
+			<-g.changed
+			continue
+		}
+
+		select {
+		case <-g.changed:
+		case <-time.After(g.Grace):
+			g.mu.Lock()
+			stable := version == g.version
+			g.mu.Unlock()
+			if stable {
+				g.finish(g.deadlock())
+				return
+			}
+		}
+	}
+}
+
+// stuck looks at what every blocked machine is waiting on. If they are all
+// waiting on channels between machines in the group it is visible, and stuck
+// if nothing can be passed over any of them. That is when no value is part
+// way through being handed over, and nobody is waiting on a channel that has
+// a value or room for one, or that somebody else is waiting on the other end
+// of. It must be called with the lock held.
+func (g *Group) stuck() (visible, stuck bool) {
+	reading := make(map[*channel]bool)
+	writing := make(map[*channel]bool)
+	for _, m := range g.members {
+		if m.stopped {
+			continue
+		}
+		switch {
+		case m.waiting == NeedsInput && m.in != nil && m.in.internal():
+			reading[m.in] = true
+		case m.waiting == HasOutput && m.out != nil && m.out.internal():
+			writing[m.out] = true
+		default:
+			return false, false
+		}
+	}
+
+	for _, c := range g.channels {
+		if c.internal() && c.writes-c.reads != int64(c.len()) {
+			return true, false
+		}
+	}
+	for c := range reading {
+		if writing[c] || c.len() > 0 {
+			return true, false
+		}
+	}
+	for c := range writing {
+		if c.len() < c.cap() {
+			return true, false
+		}
+	}
+	return true, true
+}
+
+// finish records the outcome, stops anything still running and closes done.
+// A machine that stopped with an error is the more useful thing to report,
+// as it is likely why the others got stuck.
+func (g *Group) finish(err error) {
+	g.mu.Lock()
+	if g.first != nil {
+		err = g.first
+	}
+	g.err = err
+	g.finished = true
+	g.mu.Unlock()
+	g.cancel()
+	close(g.done)
+}
+
+func (g *Group) deadlock() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	stuck := make([]Blocked, 0)
+	for _, m := range g.members {
+		if !m.stopped || m.stranded {
+			stuck = append(stuck, Blocked{
+				Name:    m.name,
+				Address: m.address,
+				Output:  m.waiting == HasOutput,
+			})
+		}
+	}
+	return &DeadlockError{Machines: stuck}
+}
@@ -0,0 +1,178 @@
+package intcode
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestGroupDeadlock(t *testing.T) {
+	// each machine echoes a value from the other, so both wait forever
+	echo := []int64{3, 7, 4, 7, 99, 0, 0, 0}
+	a := make(chan int64)
+	b := make(chan int64)
+
+	machines := NewGroup(context.Background())
+	machines.Grace = 10 * time.Millisecond
+	machines.Go("a", NewWithIO(echo, ChannelInput(a), ChannelOutput(b)))
+	machines.Go("b", NewWithIO(echo, ChannelInput(b), ChannelOutput(a)))
+
+	var deadlock *DeadlockError
+	if err := machines.Wait(); !errors.As(err, &deadlock) {
+		t.Fatalf("expected a deadlock but got %v", err)
+	}
+	expected := []Blocked{{Name: "a", Address: 0}, {Name: "b", Address: 0}}
+	if !reflect.DeepEqual(deadlock.Machines, expected) {
+		t.Errorf("expected %v but got %v", expected, deadlock.Machines)
+	}
+}
+
+func TestGroupBlockedOutput(t *testing.T) {
+	// nobody reads the output
+	machines := NewGroup(context.Background())
+	machines.Grace = 10 * time.Millisecond
+	machines.Go("a", NewWithIO([]int64{104, 1, 99}, ChannelInput(nil), ChannelOutput(make(chan int64))))
+
+	var deadlock *DeadlockError
+	if err := machines.Wait(); !errors.As(err, &deadlock) {
+		t.Fatalf("expected a deadlock but got %v", err)
+	}
+	expected := []Blocked{{Name: "a", Address: 0, Output: true}}
+	if !reflect.DeepEqual(deadlock.Machines, expected) {
+		t.Errorf("expected %v but got %v", expected, deadlock.Machines)
+	}
+}
+
+func TestGroupHalts(t *testing.T) {
+	a := make(chan int64)
+	b := make(chan int64, 1)
+
+	machines := NewGroup(context.Background())
+	machines.Go("a", NewWithIO([]int64{3, 7, 4, 7, 99, 0, 0, 0}, ChannelInput(a), ChannelOutput(b)))
+	a <- 42
+
+	if err := machines.Wait(); err != nil {
+		t.Fatal(err)
+	}
+	if val := <-b; val != 42 {
+		t.Errorf("expected 42 but got %d", val)
+	}
+}
+
+func TestGroupLateMachine(t *testing.T) {
+	// the first machine halting before the second is added doesn't finish
+	// the group as nothing is watching it yet
+	machines := NewGroup(context.Background())
+	machines.Go("fast", New([]int64{99}))
+	time.Sleep(20 * time.Millisecond)
+
+	input := make(chan int64, 1)
+	output := make(chan int64, 1)
+	input <- 42
+	machines.Go("slow", NewWithIO([]int64{3, 7, 4, 7, 99, 0, 0, 0}, ChannelInput(input), ChannelOutput(output)))
+
+	if err := machines.Wait(); err != nil {
+		t.Fatal(err)
+	}
+	if len(output) != 1 || <-output != 42 {
+		t.Errorf("expected the slow machine to run")
+	}
+
+	late := New([]int64{99})
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected adding a machine after Wait to panic")
+		}
+		if late.member != nil {
+			t.Errorf("expected the rejected machine to be left alone")
+		}
+	}()
+	machines.Go("late", late)
+}
+
+func TestGroupDeadlockWithoutGrace(t *testing.T) {
+	// echoes 100 values and then wants one more before halting, so with a
+	// single value going back and forth a ends up with one more than b
+	echo := []int64{3, 20, 4, 20, 1001, 21, -1, 21, 1005, 21, 0, 3, 20, 99, 0, 0, 0, 0, 0, 0, 0, 100}
+	ab := make(chan int64)
+	ba := make(chan int64, 1)
+	ba <- 7
+
+	// the channels say when they are stuck so there's no need to wait
+	machines := NewGroup(context.Background())
+	machines.Grace = time.Hour
+	machines.Go("a", NewWithIO(echo, ChannelInput(ba), ChannelOutput(ab)))
+	machines.Go("b", NewWithIO(echo, ChannelInput(ab), ChannelOutput(ba)))
+
+	var deadlock *DeadlockError
+	if err := machines.Wait(); !errors.As(err, &deadlock) {
+		t.Fatalf("expected a deadlock but got %v", err)
+	}
+	expected := []Blocked{{Name: "b", Address: 11}}
+	if !reflect.DeepEqual(deadlock.Machines, expected) {
+		t.Errorf("expected %v but got %v", expected, deadlock.Machines)
+	}
+}
+
+func TestGroupStranded(t *testing.T) {
+	// nothing is ever going to be pushed onto the queue
+	machines := NewGroup(context.Background())
+	machines.Grace = time.Hour
+	machines.Go("q", New([]int64{104, 1, 3, 0, 99}))
+
+	var deadlock *DeadlockError
+	if err := machines.Wait(); !errors.As(err, &deadlock) {
+		t.Fatalf("expected the machine to be stuck but got %v", err)
+	}
+	expected := []Blocked{{Name: "q", Address: 2}}
+	if !reflect.DeepEqual(deadlock.Machines, expected) {
+		t.Errorf("expected %v but got %v", expected, deadlock.Machines)
+	}
+}
+
+func TestBudget(t *testing.T) {
+	// loops forever without any I/O
+	prog := New([]int64{1105, 1, 0})
+	prog.SetBudget(100)
+
+	state, _, err := prog.Run(context.Background())
+	if state != Faulted {
+		t.Fatalf("expected Faulted but got %s", state)
+	}
+	var budget *BudgetError
+	if !errors.As(err, &budget) || budget.Address != 0 || budget.Budget != 100 {
+		t.Errorf("expected a budget error at address 0 but got %v", err)
+	}
+
+	// I/O resets the count
+	prog = New([]int64{3, 9, 4, 9, 1105, 1, 0, 99, 0, 0})
+	prog.SetBudget(3)
+	if state, _, err := prog.ResumeWith(1, 2); state != NeedsInput || err != nil {
+		t.Errorf("expected NeedsInput but got %s, %v", state, err)
+	}
+}
+
+func TestRunCancel(t *testing.T) {
+	input := make(chan int64)
+	prog := NewWithIO([]int64{3, 5, 4, 5, 99, 0}, ChannelInput(input), OutputFunc(func(int64) {}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, _, err := prog.Run(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the deadline to be exceeded but got %v", err)
+	}
+	if prog.Address() != 0 {
+		t.Errorf("expected the input to be retried from 0 but got %d", prog.Address())
+	}
+
+	// the machine carries on from where it was stopped
+	go func() { input <- 7 }()
+	if state, _, err := prog.Run(context.Background()); state != Halted || err != nil {
+		t.Errorf("expected Halted but got %s, %v", state, err)
+	}
+	if val := prog.Memory().Read(5); val != 7 {
+		t.Errorf("expected 7 but got %d", val)
+	}
+}
@@ -0,0 +1,405 @@
+// Package intcode implements the Intcode computer shared by the Go
+// solutions from Day 5 onwards.
+package intcode
+
+import (
+	"context"
+	"fmt"
+)
+
+type Intcode struct {
+	memory       Memory
+	address      int64
+	relativeBase int64
+	state        State
+	last         Instruction
+	err          error
+	input        Input
+	output       Output
+	tracer       Tracer
+	budget       int64
+	idle         int64
+	member       *member
+	ctx          context.Context
+	interrupted  error
+}
+
+type Instruction struct {
+	Address int64
+	Opcode  int64
+	Modes   [3]int64
+}
+
+// State describes the machine after an instruction has been executed.
+type State int
+
+const (
+	// Running means the machine can carry on with the next instruction.
+	Running State = iota
+	// NeedsInput means the input had nothing to offer. The instruction is
+	// retried when the machine is next stepped or run.
+	NeedsInput
+	// HasOutput means a value has just been written to the output.
+	HasOutput
+	// Halted means the program has finished.
+	Halted
+	// Faulted means the machine came across something it couldn't execute,
+	// or ran past its budget. The reason is available from Err as a *Fault
+	// or a *BudgetError.
+	Faulted
+)
+
+func (s State) String() string {
+	switch s {
+	case Running:
+		return "Running"
+	case NeedsInput:
+		return "NeedsInput"
+	case HasOutput:
+		return "HasOutput"
+	case Halted:
+		return "Halted"
+	case Faulted:
+		return "Faulted"
+	}
+	return fmt.Sprintf("State(%d)", int(s))
+}
+
+// New creates a machine that buffers its I/O in a pair of queues. The machine
+// pauses when it needs an input that hasn't been provided through ResumeWith
+// and any outputs can be collected with ReadOutput.
+func New(memory []int64) *Intcode {
+	return NewWithIO(memory, NewQueue(), NewQueue())
+}
+
+// NewWithIO creates a machine that reads from input and writes to output.
+// With blocking I/O, such as channels, the machine is intended to be run in
+// its own goroutine.
+func NewWithIO(memory []int64, input Input, output Output) *Intcode {
+	return NewWithMemory(NewSliceMemory(memory), input, output)
+}
+
+// NewWithMemory creates a machine that runs the program already loaded into
+// memory.
+func NewWithMemory(memory Memory, input Input, output Output) *Intcode {
+	return &Intcode{
+		memory: memory,
+		input:  input,
+		output: output,
+	}
+}
+
+// Step executes a single instruction and returns it along with the state of
+// the machine afterwards. Once halted or faulted the machine stays that way
+// and the last instruction is returned again, as is the fault if there was
+// one.
+func (prog *Intcode) Step() (State, Instruction, error) {
+	if prog.state == Halted || prog.state == Faulted {
+		return prog.state, prog.last, prog.err
+	}
+
+	word := prog.memory.Read(prog.address)
+	instruction := parseInstruction(word)
+	instruction.Address = prog.address
+	prog.last = instruction
+
+	if err := prog.validate(word, &instruction); err != nil {
+		prog.state = Faulted
+		prog.err = err
+		return prog.state, prog.last, prog.err
+	}
+
+	prog.state = Running
+	prog.interrupted = nil
+	if prog.tracer != nil {
+		ev := prog.traceEvent(instruction)
+		prog.executeInstruction(&instruction)
+		prog.traceComplete(ev)
+	} else {
+		prog.executeInstruction(&instruction)
+	}
+
+	if prog.interrupted != nil {
+		// the instruction is tried again when the machine is next run
+		return prog.state, prog.last, prog.interrupted
+	}
+
+	switch {
+	case prog.state == NeedsInput:
+		// nothing was executed so it doesn't count
+	case instruction.Opcode == OpInput || instruction.Opcode == OpOutput:
+		prog.idle = 0
+	default:
+		prog.idle++
+		if prog.budget > 0 && prog.idle > prog.budget {
+			prog.state = Faulted
+			prog.err = &BudgetError{Address: prog.address, Budget: prog.budget}
+			return prog.state, prog.last, prog.err
+		}
+	}
+
+	return prog.state, prog.last, nil
+}
+
+// Run steps through the program until it halts, faults or needs an input
+// that isn't available yet. Outputs don't stop the machine.
+//
+// Cancelling the context also stops the machine, in which case the context's
+// error is returned. An instruction waiting on a ContextInput or
+// ContextOutput is abandoned and tried again if the machine is run again.
+func (prog *Intcode) Run(ctx context.Context) (State, Instruction, error) {
+	prog.ctx = ctx
+	defer func() { prog.ctx = nil }()
+
+	done := ctx.Done()
+	for {
+		if done != nil {
+			select {
+			case <-done:
+				return prog.state, prog.last, ctx.Err()
+			default:
+			}
+		}
+
+		state, instruction, err := prog.Step()
+		if err != nil || (state != Running && state != HasOutput) {
+			return state, instruction, err
+		}
+	}
+}
+
+// ResumeWith queues the values as inputs and continues running. This is
+// only possible when the input is a Queue, as it is for machines made by New.
+func (prog *Intcode) ResumeWith(vals ...int64) (State, Instruction, error) {
+	queue, ok := prog.input.(*Queue)
+	if !ok {
+		panic("intcode: ResumeWith requires a Queue input")
+	}
+	queue.Push(vals...)
+	return prog.Run(context.Background())
+}
+
+// ReadOutput takes the oldest output that hasn't yet been read. Nothing is
+// returned unless the output is a Queue, as it is for machines made by New.
+func (prog *Intcode) ReadOutput() (bool, int64) {
+	if queue, ok := prog.output.(*Queue); ok {
+		val, ok := queue.Read()
+		return ok, val
+	}
+	return false, 0
+}
+
+// SetBudget limits how many instructions in a row the machine can execute
+// without any I/O before it faults. A program that goes that long without
+// reading or writing anything is likely stuck in a loop. Zero, the default,
+// means no limit.
+func (prog *Intcode) SetBudget(budget int64) {
+	prog.budget = budget
+	prog.idle = 0
+}
+
+func (prog *Intcode) State() State {
+	return prog.state
+}
+
+func (prog *Intcode) Halted() bool {
+	return prog.state == Halted
+}
+
+// Err returns the fault that stopped the machine, if any.
+func (prog *Intcode) Err() error {
+	return prog.err
+}
+
+// Address returns the address of the next instruction to be executed.
+func (prog *Intcode) Address() int64 {
+	return prog.address
+}
+
+func (prog *Intcode) RelativeBase() int64 {
+	return prog.relativeBase
+}
+
+func (prog *Intcode) SetRelativeBase(base int64) {
+	prog.relativeBase = base
+}
+
+// Memory gives direct access to the memory of the machine, which is mostly
+// useful for inspecting it.
+func (prog *Intcode) Memory() Memory {
+	return prog.memory
+}
+
+// Input returns the input the machine reads from. This is mostly useful to
+// get hold of the copied Queue of a clone.
+func (prog *Intcode) Input() Input {
+	return prog.input
+}
+
+// Output returns the output the machine writes to.
+func (prog *Intcode) Output() Output {
+	return prog.output
+}
+
+func (prog *Intcode) opAdd(a, b, c int64) {
+	prog.memory.Write(c, prog.memory.Read(a)+prog.memory.Read(b))
+	prog.address += 4
+}
+
+func (prog *Intcode) opMultiply(a, b, c int64) {
+	prog.memory.Write(c, prog.memory.Read(a)*prog.memory.Read(b))
+	prog.address += 4
+}
+
+func (prog *Intcode) opInput(a int64) {
+	prog.blocked(NeedsInput)
+	val, ok, err := prog.read()
+	prog.unblocked(err == nil && ok)
+
+	if err != nil {
+		prog.interrupted = err
+	} else if ok {
+		prog.memory.Write(a, val)
+		prog.address += 2
+	} else {
+		prog.state = NeedsInput
+	}
+}
+
+func (prog *Intcode) opOutput(a int64) {
+	prog.blocked(HasOutput)
+	err := prog.write(prog.memory.Read(a))
+	prog.unblocked(err == nil)
+
+	if err != nil {
+		prog.interrupted = err
+		return
+	}
+	prog.address += 2
+	prog.state = HasOutput
+}
+
+func (prog *Intcode) opJmpTrue(a, b int64) {
+	if prog.memory.Read(a) != 0 {
+		prog.address = prog.memory.Read(b)
+	} else {
+		prog.address += 3
+	}
+}
+
+func (prog *Intcode) opJmpFalse(a, b int64) {
+	if prog.memory.Read(a) == 0 {
+		prog.address = prog.memory.Read(b)
+	} else {
+		prog.address += 3
+	}
+}
+
+func (prog *Intcode) opLessThan(a, b, c int64) {
+	if prog.memory.Read(a) < prog.memory.Read(b) {
+		prog.memory.Write(c, 1)
+	} else {
+		prog.memory.Write(c, 0)
+	}
+	prog.address += 4
+}
+
+func (prog *Intcode) opEquals(a, b, c int64) {
+	if prog.memory.Read(a) == prog.memory.Read(b) {
+		prog.memory.Write(c, 1)
+	} else {
+		prog.memory.Write(c, 0)
+	}
+	prog.address += 4
+}
+
+func (prog *Intcode) opAdjustBaseOffset(a int64) {
+	prog.relativeBase += prog.memory.Read(a)
+	prog.address += 2
+}
+
+func (prog *Intcode) opHalt() {
+	prog.address += 1
+	prog.state = Halted
+}
+
+// param resolves the address referred to by the nth parameter so that reads
+// and writes are handled the same way regardless of mode. Modes have already
+// been checked by validate.
+func (prog *Intcode) param(modes [3]int64, n int64) (res int64) {
+	switch modes[n] {
+	case ModePosition:
+		res = prog.memory.Read(prog.address + n + 1)
+	case ModeImmediate:
+		res = prog.address + n + 1
+	case ModeRelative:
+		res = prog.memory.Read(prog.address+n+1) + prog.relativeBase
+	}
+	return
+}
+
+func (prog *Intcode) executeInstruction(inst *Instruction) {
+	switch inst.Opcode {
+	case OpAdd:
+		prog.opAdd(
+			prog.param(inst.Modes, 0),
+			prog.param(inst.Modes, 1),
+			prog.param(inst.Modes, 2),
+		)
+	case OpMultiply:
+		prog.opMultiply(
+			prog.param(inst.Modes, 0),
+			prog.param(inst.Modes, 1),
+			prog.param(inst.Modes, 2),
+		)
+	case OpInput:
+		prog.opInput(
+			prog.param(inst.Modes, 0),
+		)
+	case OpOutput:
+		prog.opOutput(
+			prog.param(inst.Modes, 0),
+		)
+	case OpJmpTrue:
+		prog.opJmpTrue(
+			prog.param(inst.Modes, 0),
+			prog.param(inst.Modes, 1),
+		)
+	case OpJmpFalse:
+		prog.opJmpFalse(
+			prog.param(inst.Modes, 0),
+			prog.param(inst.Modes, 1),
+		)
+	case OpLessThan:
+		prog.opLessThan(
+			prog.param(inst.Modes, 0),
+			prog.param(inst.Modes, 1),
+			prog.param(inst.Modes, 2),
+		)
+	case OpEquals:
+		prog.opEquals(
+			prog.param(inst.Modes, 0),
+			prog.param(inst.Modes, 1),
+			prog.param(inst.Modes, 2),
+		)
+	case OpAdjustRB:
+		prog.opAdjustBaseOffset(
+			prog.param(inst.Modes, 0),
+		)
+	case OpHalt:
+		prog.opHalt()
+	}
+}
+
+func parseInstruction(num int64) Instruction {
+	var digits [5]int64
+	for i := 0; i < 5 && num > 0; i++ {
+		digits[i] = num % 10
+		num /= 10
+	}
+	opcode := digits[1]*10 + digits[0]
+	return Instruction{
+		Opcode: opcode,
+		Modes:  [3]int64{digits[2], digits[3], digits[4]},
+	}
+}
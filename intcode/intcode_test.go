@@ -0,0 +1,132 @@
+package intcode
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestSelfTests(t *testing.T) {
+	// the puzzle inputs check the machine themselves, outputting the
+	// instructions that misbehaved rather than the answer
+	tests := []struct {
+		name     string
+		path     string
+		input    int64
+		expected []int64
+	}{
+		{"BOOST", "../inputs/09.txt", 1, []int64{2870072642}},
+		{"Diagnostic", "../inputs/05.txt", 5, []int64{14195011}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			prog := New(loadProgram(t, test.path))
+			if state, _, err := prog.ResumeWith(test.input); state != Halted {
+				t.Fatalf("expected to halt but was %s: %v", state, err)
+			}
+
+			var outputs []int64
+			for ok, val := prog.ReadOutput(); ok; ok, val = prog.ReadOutput() {
+				outputs = append(outputs, val)
+			}
+			if !reflect.DeepEqual(outputs, test.expected) {
+				t.Errorf("expected %v but got %v", test.expected, outputs)
+			}
+		})
+	}
+}
+
+func TestStep(t *testing.T) {
+	// outputs 7, then echoes an input and halts
+	prog := New([]int64{104, 7, 3, 9, 4, 9, 99, 0, 0, 0})
+	input := prog.Input().(*Queue)
+
+	steps := []struct {
+		push        []int64
+		state       State
+		instruction int64
+		address     int64
+	}{
+		{nil, HasOutput, 0, 2},
+		{nil, NeedsInput, 2, 2},
+		{nil, NeedsInput, 2, 2},
+		{[]int64{5}, Running, 2, 4},
+		{nil, HasOutput, 4, 6},
+		{nil, Halted, 6, 7},
+		{nil, Halted, 6, 7},
+	}
+
+	for i, step := range steps {
+		input.Push(step.push...)
+		state, inst, err := prog.Step()
+		if err != nil {
+			t.Fatalf("step %d: %v", i, err)
+		}
+		if state != step.state || prog.State() != step.state {
+			t.Errorf("step %d: expected %s but got %s", i, step.state, state)
+		}
+		if inst.Address != step.instruction {
+			t.Errorf("step %d: expected the instruction at %d but got %d", i, step.instruction, inst.Address)
+		}
+		if prog.Address() != step.address {
+			t.Errorf("step %d: expected to be at %d but got %d", i, step.address, prog.Address())
+		}
+	}
+	if val := prog.Memory().Read(9); val != 5 {
+		t.Errorf("expected the input to be stored but got %d", val)
+	}
+}
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name    string
+		program []int64
+		state   State
+		outputs []int64
+		address int64
+	}{
+		{"Halts", []int64{104, 1, 104, 2, 99}, Halted, []int64{1, 2}, 5},
+		{"NeedsInput", []int64{104, 1, 3, 0}, NeedsInput, []int64{1}, 2},
+		{"Faults", []int64{104, 1, 104, 2, 42}, Faulted, []int64{1, 2}, 4},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			prog := New(test.program)
+			state, inst, err := prog.Run(context.Background())
+			if state != test.state {
+				t.Fatalf("expected %s but got %s: %v", test.state, state, err)
+			}
+			if prog.Address() != test.address {
+				t.Errorf("expected to stop at %d but got %d", test.address, prog.Address())
+			}
+
+			var outputs []int64
+			for ok, val := prog.ReadOutput(); ok; ok, val = prog.ReadOutput() {
+				outputs = append(outputs, val)
+			}
+			if !reflect.DeepEqual(outputs, test.outputs) {
+				t.Errorf("expected %v but got %v", test.outputs, outputs)
+			}
+
+			// a finished machine stays that way
+			if state == Halted || state == Faulted {
+				again, last, _ := prog.Step()
+				if again != state || last != inst {
+					t.Errorf("expected %s at %v again but got %s at %v", state, inst, again, last)
+				}
+			}
+		})
+	}
+}
+
+func TestResumeWithPanics(t *testing.T) {
+	prog := NewWithIO([]int64{3, 0, 99}, InputFunc(func() (int64, bool) { return 0, false }), NewQueue())
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected ResumeWith to panic without a Queue input")
+		}
+	}()
+	prog.ResumeWith(1)
+}
@@ -0,0 +1,180 @@
+package intcode
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+)
+
+// Input supplies the values consumed by the input instruction. Read should
+// report false when no value is available, which pauses the machine until it
+// is resumed.
+type Input interface {
+	Read() (int64, bool)
+}
+
+// Output receives every value produced by the output instruction.
+type Output interface {
+	Write(val int64)
+}
+
+// ContextInput is an Input that can give up waiting for a value when a
+// context is done, returning the context's error.
+type ContextInput interface {
+	Input
+	ReadContext(ctx context.Context) (int64, bool, error)
+}
+
+// ContextOutput is an Output that can give up waiting for a value to be
+// taken when a context is done, returning the context's error.
+type ContextOutput interface {
+	Output
+	WriteContext(ctx context.Context, val int64) error
+}
+
+// read takes a value from the input, giving up if the machine is being run
+// with a context that is done and the input supports it.
+func (prog *Intcode) read() (int64, bool, error) {
+	if in, ok := prog.input.(ContextInput); ok && prog.ctx != nil {
+		return in.ReadContext(prog.ctx)
+	}
+	val, ok := prog.input.Read()
+	return val, ok, nil
+}
+
+func (prog *Intcode) write(val int64) error {
+	if out, ok := prog.output.(ContextOutput); ok && prog.ctx != nil {
+		return out.WriteContext(prog.ctx, val)
+	}
+	prog.output.Write(val)
+	return nil
+}
+
+// Queue is a FIFO buffer of values that can be used as either an Input or an
+// Output. It is what New uses for both so that a driver can push inputs and
+// pull outputs between runs.
+type Queue struct {
+	values []int64
+}
+
+func NewQueue(vals ...int64) *Queue {
+	return &Queue{values: append(make([]int64, 0, len(vals)), vals...)}
+}
+
+func (q *Queue) Push(vals ...int64) {
+	q.values = append(q.values, vals...)
+}
+
+func (q *Queue) Read() (int64, bool) {
+	if len(q.values) == 0 {
+		return 0, false
+	}
+	val := q.values[0]
+	q.values = q.values[1:]
+	return val, true
+}
+
+func (q *Queue) Write(val int64) {
+	q.values = append(q.values, val)
+}
+
+func (q *Queue) Len() int {
+	return len(q.values)
+}
+
+func (q *Queue) Clone() *Queue {
+	return NewQueue(q.values...)
+}
+
+// ChannelInput blocks until a value is received. A closed channel is treated
+// as having no more input.
+type ChannelInput <-chan int64
+
+func (c ChannelInput) Read() (int64, bool) {
+	val, ok := <-c
+	return val, ok
+}
+
+func (c ChannelInput) ReadContext(ctx context.Context) (int64, bool, error) {
+	select {
+	case val, ok := <-c:
+		return val, ok, nil
+	case <-ctx.Done():
+		return 0, false, ctx.Err()
+	}
+}
+
+// ChannelOutput blocks until the value is received.
+type ChannelOutput chan<- int64
+
+func (c ChannelOutput) Write(val int64) {
+	c <- val
+}
+
+func (c ChannelOutput) WriteContext(ctx context.Context, val int64) error {
+	select {
+	case c <- val:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// InputFunc allows a plain function to provide inputs on demand.
+type InputFunc func() (int64, bool)
+
+func (f InputFunc) Read() (int64, bool) {
+	return f()
+}
+
+// OutputFunc allows a plain function to handle outputs as they happen.
+type OutputFunc func(val int64)
+
+func (f OutputFunc) Write(val int64) {
+	f(val)
+}
+
+// ASCIIInput feeds each byte of a reader to the machine as a separate value.
+type ASCIIInput struct {
+	rdr *bufio.Reader
+}
+
+func NewASCIIInput(r io.Reader) *ASCIIInput {
+	return &ASCIIInput{rdr: bufio.NewReader(r)}
+}
+
+func (in *ASCIIInput) Read() (int64, bool) {
+	b, err := in.rdr.ReadByte()
+	if err != nil {
+		return 0, false
+	}
+	return int64(b), true
+}
+
+// ASCIIOutput writes values as characters. Anything outside of the ASCII range
+// can't be a character so it is written as a number on its own line instead.
+// The first error encountered is kept and can be checked with Err.
+type ASCIIOutput struct {
+	w   io.Writer
+	err error
+}
+
+func NewASCIIOutput(w io.Writer) *ASCIIOutput {
+	return &ASCIIOutput{w: w}
+}
+
+func (out *ASCIIOutput) Write(val int64) {
+	if out.err != nil {
+		return
+	}
+	if val >= 0 && val < 128 {
+		_, out.err = out.w.Write([]byte{byte(val)})
+	} else {
+		_, out.err = fmt.Fprintln(out.w, val)
+	}
+}
+
+func (out *ASCIIOutput) Err() error {
+	return out.err
+}
@@ -0,0 +1,87 @@
+package intcode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestAdapters(t *testing.T) {
+	// echoes every input until there is nothing left
+	echo := []int64{3, 9, 4, 9, 1105, 1, 0, 99, 0, 0}
+
+	tests := []struct {
+		name     string
+		setup    func() (Input, Output, func() string)
+		expected string
+	}{
+		{"ASCII", func() (Input, Output, func() string) {
+			var out strings.Builder
+			return NewASCIIInput(strings.NewReader("hi\n")), NewASCIIOutput(&out), out.String
+		}, "hi\n"},
+		{"ASCIIOutputNumbers", func() (Input, Output, func() string) {
+			var out strings.Builder
+			return NewQueue('o', 'k', 128, -1, 1000), NewASCIIOutput(&out), out.String
+		}, "ok128\n-1\n1000\n"},
+		{"ClosedChannel", func() (Input, Output, func() string) {
+			input := make(chan int64, 2)
+			input <- 1
+			input <- 2
+			close(input)
+			output := make(chan int64, 2)
+			return ChannelInput(input), ChannelOutput(output), func() string {
+				return fmt.Sprint(<-output, <-output)
+			}
+		}, "1 2"},
+		{"Funcs", func() (Input, Output, func() string) {
+			next := int64(0)
+			var seen []int64
+			input := InputFunc(func() (int64, bool) {
+				next++
+				return next * 10, next <= 3
+			})
+			return input, OutputFunc(func(val int64) { seen = append(seen, val) }), func() string {
+				return fmt.Sprint(seen)
+			}
+		}, "[10 20 30]"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			input, output, result := test.setup()
+			prog := NewWithIO(echo, input, output)
+			if state, _, err := prog.Run(context.Background()); state != NeedsInput || err != nil {
+				t.Fatalf("expected NeedsInput but got %s, %v", state, err)
+			}
+			if got := result(); got != test.expected {
+				t.Errorf("expected %q but got %q", test.expected, got)
+			}
+		})
+	}
+}
+
+type failingWriter struct {
+	writes int
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	w.writes++
+	return 0, errors.New("disk full")
+}
+
+func TestASCIIOutputErr(t *testing.T) {
+	w := &failingWriter{}
+	out := NewASCIIOutput(w)
+	out.Write('a')
+	out.Write('b')
+	out.Write(500)
+
+	if err := out.Err(); err == nil || err.Error() != "disk full" {
+		t.Errorf("expected the write error but got %v", err)
+	}
+	if w.writes != 1 {
+		t.Errorf("expected writing to stop after the error but wrote %d times", w.writes)
+	}
+}
@@ -0,0 +1,109 @@
+package intcode
+
+// Memory is the storage a program is loaded into and works on. Any address
+// can be read, with addresses that were never written reading as 0. Clone
+// returns an independent copy.
+type Memory interface {
+	Read(addr int64) int64
+	Write(addr, val int64)
+	Clone() Memory
+}
+
+// MapMemory stores every address in a map. It copes with any address but
+// every access costs a hash lookup.
+type MapMemory map[int64]int64
+
+func NewMapMemory(program []int64) MapMemory {
+	mem := make(MapMemory, len(program))
+
+	for i, n := range program {
+		mem[int64(i)] = n
+	}
+
+	return mem
+}
+
+func (mem MapMemory) Read(addr int64) int64 {
+	return mem[addr]
+}
+
+func (mem MapMemory) Write(addr, val int64) {
+	mem[addr] = val
+}
+
+func (mem MapMemory) Clone() Memory {
+	clone := make(MapMemory, len(mem))
+	for addr, val := range mem {
+		clone[addr] = val
+	}
+	return clone
+}
+
+// denseLimit is the highest address kept in the slice. A program writing far
+// beyond this would otherwise have us allocate everything in between.
+const denseLimit = 1 << 20
+
+// SliceMemory stores addresses in a slice that grows as higher addresses are
+// written. Anything beyond denseLimit, or below zero, goes into a map instead
+// so that sparse high addresses are still cheap.
+type SliceMemory struct {
+	dense  []int64
+	sparse map[int64]int64
+}
+
+func NewSliceMemory(program []int64) *SliceMemory {
+	dense := make([]int64, len(program))
+	copy(dense, program)
+	return &SliceMemory{dense: dense}
+}
+
+func (mem *SliceMemory) Read(addr int64) int64 {
+	if addr >= 0 && addr < int64(len(mem.dense)) {
+		return mem.dense[addr]
+	}
+	if addr >= 0 && addr < denseLimit {
+		return 0
+	}
+	return mem.sparse[addr]
+}
+
+func (mem *SliceMemory) Write(addr, val int64) {
+	if addr < 0 || addr >= denseLimit {
+		if mem.sparse == nil {
+			mem.sparse = make(map[int64]int64)
+		}
+		mem.sparse[addr] = val
+		return
+	}
+
+	if addr >= int64(len(mem.dense)) {
+		mem.grow(addr + 1)
+	}
+	mem.dense[addr] = val
+}
+
+func (mem *SliceMemory) Clone() Memory {
+	clone := NewSliceMemory(mem.dense)
+	if mem.sparse != nil {
+		clone.sparse = make(map[int64]int64, len(mem.sparse))
+		for addr, val := range mem.sparse {
+			clone.sparse[addr] = val
+		}
+	}
+	return clone
+}
+
+func (mem *SliceMemory) grow(size int64) {
+	if size <= int64(cap(mem.dense)) {
+		mem.dense = mem.dense[:size]
+		return
+	}
+
+	// double the capacity to avoid growing a word at a time as a program
+	// walks upwards through memory
+	capacity := max(size, 2*int64(cap(mem.dense)))
+	capacity = min(capacity, denseLimit)
+	dense := make([]int64, size, capacity)
+	copy(dense, mem.dense)
+	mem.dense = dense
+}
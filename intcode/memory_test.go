@@ -0,0 +1,80 @@
+package intcode
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func loadProgram(tb testing.TB, path string) []int64 {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	program := make([]int64, 0)
+	for _, num := range strings.Split(strings.TrimSpace(string(data)), ",") {
+		value, err := strconv.ParseInt(num, 10, 64)
+		if err != nil {
+			tb.Fatal(err)
+		}
+		program = append(program, value)
+	}
+	return program
+}
+
+func TestSliceMemorySparse(t *testing.T) {
+	mem := NewSliceMemory([]int64{1, 2, 3})
+	addrs := []int64{0, 2, 3, 100, denseLimit - 1, denseLimit, 1 << 40, -5}
+
+	for i, addr := range addrs {
+		mem.Write(addr, int64(i+10))
+	}
+	for i, addr := range addrs {
+		if val := mem.Read(addr); val != int64(i+10) {
+			t.Errorf("address %d: expected %d but got %d", addr, i+10, val)
+		}
+	}
+	if val := mem.Read(99); val != 0 {
+		t.Errorf("unwritten address: expected 0 but got %d", val)
+	}
+	if val := mem.Read(1<<40 + 1); val != 0 {
+		t.Errorf("unwritten sparse address: expected 0 but got %d", val)
+	}
+}
+
+var benchmarkPrograms = []struct {
+	name   string
+	path   string
+	inputs []int64
+}{
+	{"Day05", "../inputs/05.txt", []int64{5}},
+	{"Day09", "../inputs/09.txt", []int64{2}},
+	{"Day13", "../inputs/13.txt", []int64{}},
+}
+
+func BenchmarkMemory(b *testing.B) {
+	memories := []struct {
+		name string
+		load func([]int64) Memory
+	}{
+		{"Map", func(program []int64) Memory { return NewMapMemory(program) }},
+		{"Slice", func(program []int64) Memory { return NewSliceMemory(program) }},
+	}
+
+	for _, bench := range benchmarkPrograms {
+		program := loadProgram(b, bench.path)
+		for _, memory := range memories {
+			b.Run(bench.name+"/"+memory.name, func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					prog := NewWithMemory(memory.load(program), NewQueue(bench.inputs...), NewQueue())
+					if state, _, err := prog.Run(context.Background()); state != Halted {
+						b.Fatalf("expected to halt but was %s: %v", state, err)
+					}
+				}
+			})
+		}
+	}
+}
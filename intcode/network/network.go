@@ -0,0 +1,218 @@
+// Package network connects Intcode machines together so that the outputs of
+// one become the inputs of others, such as the amplifiers of Day 7.
+//
+// Machines are run in turn rather than in their own goroutines. Each runs
+// until it needs an input nobody has sent yet and then its outputs are
+// passed along every edge leaving it. This carries on until every machine
+// has halted, or until none of them can do anything more.
+package network
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/dcoxall/advent-of-code-2019/intcode"
+)
+
+type node struct {
+	name   string
+	prog   *intcode.Intcode
+	input  *intcode.Queue
+	output *intcode.Queue
+	edges  []*edge
+	halted bool
+	sink   bool
+}
+
+type edge struct {
+	from, to *node
+	last     int64
+	sent     bool
+}
+
+type Network struct {
+	nodes  map[string]*node
+	order  []*node
+	edges  map[[2]string]*edge
+	budget int64
+}
+
+func New() *Network {
+	return &Network{
+		nodes: make(map[string]*node),
+		edges: make(map[[2]string]*edge),
+	}
+}
+
+func (n *Network) add(name string) (*node, error) {
+	if _, exists := n.nodes[name]; exists {
+		return nil, fmt.Errorf("network: node %s already exists", name)
+	}
+	nd := &node{name: name}
+	n.nodes[name] = nd
+	n.order = append(n.order, nd)
+	return nd, nil
+}
+
+// AddNode adds a machine running its own copy of program. Any seeds are the
+// first inputs it receives, ahead of anything sent along an edge.
+func (n *Network) AddNode(name string, program []int64, seeds ...int64) error {
+	nd, err := n.add(name)
+	if err != nil {
+		return err
+	}
+	nd.input = intcode.NewQueue(seeds...)
+	nd.output = intcode.NewQueue()
+	nd.prog = intcode.NewWithIO(program, nd.input, nd.output)
+	nd.prog.SetBudget(n.budget)
+	return nil
+}
+
+// SetBudget limits how many instructions each machine can execute without
+// any I/O, see Intcode.SetBudget. Without one a machine stuck in a loop
+// stops the whole network from making progress.
+func (n *Network) SetBudget(budget int64) {
+	n.budget = budget
+	for _, nd := range n.order {
+		if nd.prog != nil {
+			nd.prog.SetBudget(budget)
+		}
+	}
+}
+
+// AddSink adds a node that only collects values, useful for the final output
+// of a network.
+func (n *Network) AddSink(name string) error {
+	nd, err := n.add(name)
+	if err != nil {
+		return err
+	}
+	nd.sink = true
+	nd.halted = true
+	return nil
+}
+
+// Connect sends every output of from to to. A node can have many edges in
+// either direction, with inputs arriving from several nodes being read in
+// the order they were sent.
+func (n *Network) Connect(from, to string) error {
+	src, ok := n.nodes[from]
+	if !ok {
+		return fmt.Errorf("network: no node %s", from)
+	}
+	dst, ok := n.nodes[to]
+	if !ok {
+		return fmt.Errorf("network: no node %s", to)
+	}
+	if src.sink {
+		return fmt.Errorf("network: sink %s has no outputs", from)
+	}
+	if _, exists := n.edges[[2]string{from, to}]; exists {
+		return fmt.Errorf("network: %s is already connected to %s", from, to)
+	}
+
+	e := &edge{from: src, to: dst}
+	src.edges = append(src.edges, e)
+	n.edges[[2]string{from, to}] = e
+	return nil
+}
+
+// Machine returns the machine for a node so that it can be inspected or
+// traced, or nil for a sink or unknown node.
+func (n *Network) Machine(name string) *intcode.Intcode {
+	if nd, ok := n.nodes[name]; ok {
+		return nd.prog
+	}
+	return nil
+}
+
+// Last returns the most recent value sent from one node to another.
+func (n *Network) Last(from, to string) (int64, bool) {
+	if e, ok := n.edges[[2]string{from, to}]; ok {
+		return e.last, e.sent
+	}
+	return 0, false
+}
+
+// DeadlockError is returned when the machines that haven't halted are all
+// waiting for input that will never arrive.
+type DeadlockError struct {
+	Nodes []intcode.Blocked
+}
+
+func (e *DeadlockError) Error() string {
+	stuck := make([]string, len(e.Nodes))
+	for i, b := range e.Nodes {
+		stuck[i] = fmt.Sprintf("%s at %d", b.Name, b.Address)
+	}
+	return fmt.Sprintf("network: deadlock, waiting for input: %s", strings.Join(stuck, ", "))
+}
+
+// Run runs the network until it settles. That is when every machine has
+// halted, which returns nil, or when the rest are stuck waiting for input,
+// which returns a *DeadlockError. Cancelling the context stops the network
+// between instructions and returns the context's error.
+func (n *Network) Run(ctx context.Context) error {
+	for {
+		progress := false
+		running := 0
+
+		for _, nd := range n.order {
+			if nd.halted {
+				continue
+			}
+
+			pending := nd.input.Len()
+			state, _, err := nd.prog.Run(ctx)
+			if err != nil {
+				if err == ctx.Err() {
+					return err
+				}
+				return fmt.Errorf("network: node %s: %w", nd.name, err)
+			}
+
+			if nd.input.Len() < pending || nd.output.Len() > 0 {
+				progress = true
+			}
+			n.deliver(nd)
+
+			if state == intcode.Halted {
+				nd.halted = true
+				progress = true
+			} else {
+				running++
+			}
+		}
+
+		if running == 0 {
+			return nil
+		}
+		if !progress {
+			return n.deadlock()
+		}
+	}
+}
+
+// deliver passes everything a node has output along its edges.
+func (n *Network) deliver(nd *node) {
+	for val, ok := nd.output.Read(); ok; val, ok = nd.output.Read() {
+		for _, e := range nd.edges {
+			e.last = val
+			e.sent = true
+			if !e.to.sink {
+				e.to.input.Push(val)
+			}
+		}
+	}
+}
+
+func (n *Network) deadlock() error {
+	stuck := make([]intcode.Blocked, 0)
+	for _, nd := range n.order {
+		if !nd.halted {
+			stuck = append(stuck, intcode.Blocked{Name: nd.name, Address: nd.prog.Address()})
+		}
+	}
+	return &DeadlockError{Nodes: stuck}
+}
@@ -0,0 +1,152 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/dcoxall/advent-of-code-2019/intcode"
+)
+
+// reads a value, writes it back out and halts
+var echo = []int64{3, 7, 4, 7, 99, 0, 0, 0}
+
+func TestDeadlock(t *testing.T) {
+	tests := []struct {
+		name     string
+		first    []int64
+		expected []intcode.Blocked
+		message  string
+	}{
+		// each echoes the other so neither goes first
+		{"Echo", echo, []intcode.Blocked{{Name: "A", Address: 0}, {Name: "B", Address: 0}},
+			"network: deadlock, waiting for input: A at 0, B at 0"},
+		// A wants two values back but B only ever sends one
+		{"Starved", []int64{104, 1, 3, 20, 3, 20, 99}, []intcode.Blocked{{Name: "A", Address: 4}},
+			"network: deadlock, waiting for input: A at 4"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			net := New()
+			net.AddNode("A", test.first)
+			net.AddNode("B", echo)
+			net.Connect("A", "B")
+			net.Connect("B", "A")
+
+			var deadlock *DeadlockError
+			err := net.Run(context.Background())
+			if !errors.As(err, &deadlock) {
+				t.Fatalf("expected a deadlock but got %v", err)
+			}
+			if !reflect.DeepEqual(deadlock.Nodes, test.expected) {
+				t.Errorf("expected %v but got %v", test.expected, deadlock.Nodes)
+			}
+			if err.Error() != test.message {
+				t.Errorf("expected %q but got %q", test.message, err.Error())
+			}
+		})
+	}
+}
+
+func TestFanIn(t *testing.T) {
+	// reads a then b and outputs a*10 + b, so the order matters
+	join := []int64{3, 17, 3, 18, 1002, 17, 10, 17, 1, 17, 18, 19, 4, 19, 99, 0, 0, 0, 0, 0}
+
+	net := New()
+	net.AddNode("join", join)
+	net.AddNode("Y", echo, 4)
+	net.AddNode("X", echo, 3)
+	net.AddSink("out")
+	net.Connect("X", "join")
+	net.Connect("Y", "join")
+	net.Connect("join", "out")
+
+	if err := net.Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	// Y runs before X so its value arrives first
+	if val, ok := net.Last("join", "out"); !ok || val != 43 {
+		t.Errorf("expected 43 but got %d, %t", val, ok)
+	}
+	if val, ok := net.Last("X", "join"); !ok || val != 3 {
+		t.Errorf("expected 3 from X but got %d, %t", val, ok)
+	}
+	if _, ok := net.Last("join", "X"); ok {
+		t.Errorf("expected nothing on an edge that doesn't exist")
+	}
+}
+
+func TestSelfLoop(t *testing.T) {
+	// adds one to its input and feeds it back until it reaches 5
+	counter := []int64{3, 20, 1001, 20, 1, 20, 4, 20, 1008, 20, 5, 21, 1006, 21, 0, 99, 0, 0, 0, 0, 0, 0}
+
+	net := New()
+	net.AddNode("loop", counter, 0)
+	net.AddSink("out")
+	net.Connect("loop", "loop")
+	net.Connect("loop", "out")
+
+	if _, ok := net.Last("loop", "out"); ok {
+		t.Errorf("expected nothing to have been sent before running")
+	}
+	if err := net.Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if val, ok := net.Last("loop", "out"); !ok || val != 5 {
+		t.Errorf("expected 5 but got %d, %t", val, ok)
+	}
+	if !net.Machine("loop").Halted() {
+		t.Errorf("expected the machine to halt")
+	}
+}
+
+func TestBudget(t *testing.T) {
+	net := New()
+	net.AddNode("spin", []int64{1105, 1, 0})
+	net.AddSink("out")
+	net.Connect("spin", "out")
+	net.SetBudget(100)
+
+	var budget *intcode.BudgetError
+	err := net.Run(context.Background())
+	if !errors.As(err, &budget) || budget.Budget != 100 {
+		t.Fatalf("expected a budget error but got %v", err)
+	}
+	expected := "network: node spin: intcode: no I/O in 100 instructions, stopped at address 0"
+	if err.Error() != expected {
+		t.Errorf("expected %q but got %q", expected, err.Error())
+	}
+}
+
+func TestCancel(t *testing.T) {
+	net := New()
+	net.AddNode("spin", []int64{1105, 1, 0})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := net.Run(ctx); err != context.Canceled {
+		t.Errorf("expected the context to be cancelled but got %v", err)
+	}
+}
+
+func TestBuildErrors(t *testing.T) {
+	net := New()
+	net.AddNode("A", echo)
+	net.AddSink("out")
+
+	errs := map[string]error{
+		"network: node A already exists":   net.AddNode("A", echo),
+		"network: no node B":               net.Connect("A", "B"),
+		"network: sink out has no outputs": net.Connect("out", "A"),
+	}
+	net.Connect("A", "out")
+	errs["network: A is already connected to out"] = net.Connect("A", "out")
+
+	for msg, err := range errs {
+		if err == nil || err.Error() != msg {
+			t.Errorf("expected %q but got %v", msg, err)
+		}
+	}
+}
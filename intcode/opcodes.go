@@ -0,0 +1,144 @@
+package intcode
+
+import "fmt"
+
+const (
+	OpAdd      int64 = 1
+	OpMultiply int64 = 2
+	OpInput    int64 = 3
+	OpOutput   int64 = 4
+	OpJmpTrue  int64 = 5
+	OpJmpFalse int64 = 6
+	OpLessThan int64 = 7
+	OpEquals   int64 = 8
+	OpAdjustRB int64 = 9
+	OpHalt     int64 = 99
+)
+
+const (
+	ModePosition  int64 = 0
+	ModeImmediate int64 = 1
+	ModeRelative  int64 = 2
+)
+
+var mnemonics = map[int64]string{
+	OpAdd:      "ADD",
+	OpMultiply: "MUL",
+	OpInput:    "IN",
+	OpOutput:   "OUT",
+	OpJmpTrue:  "JT",
+	OpJmpFalse: "JF",
+	OpLessThan: "LT",
+	OpEquals:   "EQ",
+	OpAdjustRB: "ARB",
+	OpHalt:     "HLT",
+}
+
+// Mnemonic returns the short name used for an opcode when reading and
+// writing programs by hand, or an empty string for an unknown opcode.
+func Mnemonic(opcode int64) string {
+	return mnemonics[opcode]
+}
+
+// Opcode is the reverse of Mnemonic.
+func Opcode(mnemonic string) (int64, bool) {
+	for opcode, name := range mnemonics {
+		if name == mnemonic {
+			return opcode, true
+		}
+	}
+	return 0, false
+}
+
+// parameters returns the number of parameters taken by an opcode and
+// whether the opcode is known at all.
+func parameters(opcode int64) (int64, bool) {
+	switch opcode {
+	case OpAdd, OpMultiply, OpLessThan, OpEquals:
+		return 3, true
+	case OpJmpTrue, OpJmpFalse:
+		return 2, true
+	case OpInput, OpOutput, OpAdjustRB:
+		return 1, true
+	case OpHalt:
+		return 0, true
+	}
+	return 0, false
+}
+
+// writes reports which parameter, if any, the instruction writes to.
+func writes(opcode int64) (int64, bool) {
+	switch opcode {
+	case OpAdd, OpMultiply, OpLessThan, OpEquals:
+		return 2, true
+	case OpInput:
+		return 0, true
+	}
+	return 0, false
+}
+
+// Parameters returns the number of parameters the instruction takes.
+func (inst Instruction) Parameters() int64 {
+	count, _ := parameters(inst.Opcode)
+	return count
+}
+
+// Length returns the number of words the instruction takes up in memory.
+func (inst Instruction) Length() int64 {
+	return inst.Parameters() + 1
+}
+
+// Writes returns which parameter the instruction writes to, if any. That
+// parameter can't be in immediate mode.
+func (inst Instruction) Writes() (int64, bool) {
+	return writes(inst.Opcode)
+}
+
+func (inst Instruction) Mnemonic() string {
+	return Mnemonic(inst.Opcode)
+}
+
+// Encode builds the instruction word for the opcode and parameter modes. It
+// is the reverse of Decode.
+func (inst Instruction) Encode() int64 {
+	return inst.Opcode + inst.Modes[0]*100 + inst.Modes[1]*1000 + inst.Modes[2]*10000
+}
+
+// check returns why an instruction word can't be executed, or an empty
+// string if it can. Mode digits are only allowed for parameters the
+// instruction actually has, anything else means the word is corrupt.
+func check(word int64, inst Instruction) string {
+	count, ok := parameters(inst.Opcode)
+	if !ok {
+		return "unknown opcode"
+	}
+	if word >= 100000 {
+		return "too many digits"
+	}
+
+	for n := int64(0); n < 3; n++ {
+		mode := inst.Modes[n]
+		if n >= count && mode != ModePosition {
+			return fmt.Sprintf("mode %d for missing parameter %d", mode, n+1)
+		}
+		if mode < ModePosition || mode > ModeRelative {
+			return fmt.Sprintf("invalid mode %d for parameter %d", mode, n+1)
+		}
+	}
+	if n, ok := writes(inst.Opcode); ok && inst.Modes[n] == ModeImmediate {
+		return fmt.Sprintf("immediate mode for write parameter %d", n+1)
+	}
+
+	return ""
+}
+
+// Decode splits an instruction word into its opcode and parameter modes in
+// the same way as the machine does. An error is returned for any word the
+// machine would fault on.
+func Decode(word int64) (Instruction, error) {
+	inst := parseInstruction(word)
+	if reason := check(word, inst); reason != "" {
+		return inst, fmt.Errorf("intcode: %s in instruction %d", reason, word)
+	}
+	return inst, nil
+}
@@ -0,0 +1,42 @@
+package intcode
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Parse reads a program in the comma separated format of the puzzle inputs.
+// Line breaks and other whitespace between values are ignored.
+func Parse(r io.Reader) ([]int64, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := strings.FieldsFunc(string(data), func(c rune) bool {
+		return c == ',' || unicode.IsSpace(c)
+	})
+
+	program := make([]int64, 0, len(fields))
+	for _, num := range fields {
+		value, err := strconv.ParseInt(num, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("intcode: invalid value at position %d: %w", len(program), err)
+		}
+		program = append(program, value)
+	}
+	return program, nil
+}
+
+// Format writes a program in the same comma separated format read by Parse.
+func Format(w io.Writer, program []int64) error {
+	values := make([]string, len(program))
+	for i, val := range program {
+		values[i] = strconv.FormatInt(val, 10)
+	}
+	_, err := fmt.Fprintln(w, strings.Join(values, ","))
+	return err
+}
@@ -0,0 +1,74 @@
+package intcode
+
+// Snapshot is a copy of everything about a machine at a point in time, so
+// that it can be put back with Restore as many times as needed.
+//
+// Pending I/O is only captured for inputs and outputs that are a Queue.
+// Anything else, such as a channel, is shared and can't be rewound.
+type Snapshot struct {
+	memory       Memory
+	address      int64
+	relativeBase int64
+	state        State
+	last         Instruction
+	err          error
+	idle         int64
+	input        *Queue
+	output       *Queue
+}
+
+func (prog *Intcode) Snapshot() *Snapshot {
+	snap := &Snapshot{
+		memory:       prog.memory.Clone(),
+		address:      prog.address,
+		relativeBase: prog.relativeBase,
+		state:        prog.state,
+		last:         prog.last,
+		err:          prog.err,
+		idle:         prog.idle,
+	}
+	if queue, ok := prog.input.(*Queue); ok {
+		snap.input = queue.Clone()
+	}
+	if queue, ok := prog.output.(*Queue); ok {
+		snap.output = queue.Clone()
+	}
+	return snap
+}
+
+// Restore puts the machine back to how it was when the snapshot was taken.
+func (prog *Intcode) Restore(snap *Snapshot) {
+	prog.memory = snap.memory.Clone()
+	prog.address = snap.address
+	prog.relativeBase = snap.relativeBase
+	prog.state = snap.state
+	prog.last = snap.last
+	prog.err = snap.err
+	prog.idle = snap.idle
+
+	// the queues are refilled in place so that a driver holding on to
+	// them keeps working after a restore
+	if queue, ok := prog.input.(*Queue); ok && snap.input != nil {
+		queue.values = append(queue.values[:0], snap.input.values...)
+	}
+	if queue, ok := prog.output.(*Queue); ok && snap.output != nil {
+		queue.values = append(queue.values[:0], snap.output.values...)
+	}
+}
+
+// Clone creates a separate machine that carries on from the same state. Its
+// memory and any Queue I/O are copied so the two can run independently. The
+// clone isn't part of any Group the original was running in.
+func (prog *Intcode) Clone() *Intcode {
+	clone := *prog
+	clone.memory = prog.memory.Clone()
+	clone.member = nil
+	clone.ctx = nil
+	if queue, ok := prog.input.(*Queue); ok {
+		clone.input = queue.Clone()
+	}
+	if queue, ok := prog.output.(*Queue); ok {
+		clone.output = queue.Clone()
+	}
+	return &clone
+}
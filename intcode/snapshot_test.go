@@ -0,0 +1,86 @@
+package intcode
+
+import (
+	"reflect"
+	"testing"
+)
+
+// bumps the relative base and echoes an input on every loop
+var echoLoop = []int64{109, 1, 3, 11, 4, 11, 1105, 1, 0, 99, 0, 0}
+
+func TestRestore(t *testing.T) {
+	prog := New(echoLoop)
+	if state, _, err := prog.ResumeWith(5); state != NeedsInput || err != nil {
+		t.Fatalf("expected NeedsInput but got %s, %v", state, err)
+	}
+	input := prog.Input().(*Queue)
+	output := prog.Output().(*Queue)
+	input.Push(8)
+	snap := prog.Snapshot()
+
+	prog.ResumeWith(9)
+	prog.Memory().Write(20, 1)
+	if prog.RelativeBase() != 4 || prog.Memory().Read(11) != 9 {
+		t.Fatalf("expected the machine to move on but it didn't")
+	}
+
+	prog.Restore(snap)
+	if prog.Address() != 2 || prog.RelativeBase() != 2 || prog.State() != NeedsInput {
+		t.Errorf("expected address 2, base 2 and NeedsInput but got %d, %d and %s",
+			prog.Address(), prog.RelativeBase(), prog.State())
+	}
+	if val := prog.Memory().Read(11); val != 5 {
+		t.Errorf("expected memory to be rewound to 5 but got %d", val)
+	}
+	if val := prog.Memory().Read(20); val != 0 {
+		t.Errorf("expected the write to be undone but got %d", val)
+	}
+	if prog.Input() != input || prog.Output() != output {
+		t.Errorf("expected the same queues to be refilled")
+	}
+	if !reflect.DeepEqual(input.values, []int64{8}) || !reflect.DeepEqual(output.values, []int64{5}) {
+		t.Errorf("expected queues [8] and [5] but got %v and %v", input.values, output.values)
+	}
+
+	// the snapshot can be used again after running on from it
+	prog.ResumeWith()
+	prog.Restore(snap)
+	prog.ResumeWith(6)
+	if !reflect.DeepEqual(output.values, []int64{5, 8, 6}) {
+		t.Errorf("expected [5 8 6] but got %v", output.values)
+	}
+}
+
+func TestClone(t *testing.T) {
+	prog := New(echoLoop)
+	prog.ResumeWith(5)
+	clone := prog.Clone()
+
+	if clone.Input() == prog.Input() || clone.Output() == prog.Output() {
+		t.Fatal("expected the clone to have its own queues")
+	}
+	clone.ResumeWith(7)
+	clone.Memory().Write(20, 1)
+
+	if prog.Memory().Read(11) != 5 || prog.Memory().Read(20) != 0 {
+		t.Errorf("expected the original memory to be untouched")
+	}
+	if prog.Address() != 2 || prog.RelativeBase() != 2 {
+		t.Errorf("expected the original at 2 with base 2 but got %d and %d", prog.Address(), prog.RelativeBase())
+	}
+	if queue := prog.Output().(*Queue); !reflect.DeepEqual(queue.values, []int64{5}) {
+		t.Errorf("expected the original output to be [5] but got %v", queue.values)
+	}
+	if queue := clone.Output().(*Queue); !reflect.DeepEqual(queue.values, []int64{5, 7}) {
+		t.Errorf("expected the clone output to be [5 7] but got %v", queue.values)
+	}
+
+	// and the other way around
+	prog.ResumeWith(6, 4)
+	if clone.Memory().Read(11) != 7 || clone.RelativeBase() != 3 {
+		t.Errorf("expected the clone to be untouched")
+	}
+	if queue := clone.Input().(*Queue); queue.Len() != 0 {
+		t.Errorf("expected the clone input to be empty but has %d", queue.Len())
+	}
+}
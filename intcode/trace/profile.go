@@ -0,0 +1,81 @@
+package trace
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/dcoxall/advent-of-code-2019/intcode"
+)
+
+// Profile is a Tracer that counts executed instructions.
+type Profile struct {
+	Total     int64
+	Opcodes   map[int64]int64
+	Addresses map[int64]int64
+
+	// the opcode last seen at each address, for the report
+	seen map[int64]int64
+}
+
+func NewProfile() *Profile {
+	return &Profile{
+		Opcodes:   make(map[int64]int64),
+		Addresses: make(map[int64]int64),
+		seen:      make(map[int64]int64),
+	}
+}
+
+func (p *Profile) Trace(ev intcode.Event) {
+	p.Total++
+	p.Opcodes[ev.Instruction.Opcode]++
+	p.Addresses[ev.Instruction.Address]++
+	p.seen[ev.Instruction.Address] = ev.Instruction.Opcode
+}
+
+// busiest sorts the keys of counts from most to least, using the key to
+// break ties so the report is stable.
+func busiest(counts map[int64]int64) []int64 {
+	keys := make([]int64, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}
+
+func (p *Profile) percent(count int64) float64 {
+	if p.Total == 0 {
+		return 0
+	}
+	return 100 * float64(count) / float64(p.Total)
+}
+
+// Report writes the count for every opcode followed by the top addresses.
+func (p *Profile) Report(w io.Writer, top int) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+
+	fmt.Fprintf(tw, "instructions executed: %d\n", p.Total)
+	fmt.Fprintf(tw, "\nopcode\tcount\t%%\t\n")
+	for _, opcode := range busiest(p.Opcodes) {
+		count := p.Opcodes[opcode]
+		fmt.Fprintf(tw, "%s\t%d\t%.1f\t\n", intcode.Mnemonic(opcode), count, p.percent(count))
+	}
+
+	fmt.Fprintf(tw, "\naddress\tcount\t%%\topcode\t\n")
+	for i, addr := range busiest(p.Addresses) {
+		if i == top {
+			break
+		}
+		count := p.Addresses[addr]
+		fmt.Fprintf(tw, "%d\t%d\t%.1f\t%s\t\n", addr, count, p.percent(count), intcode.Mnemonic(p.seen[addr]))
+	}
+
+	tw.Flush()
+}
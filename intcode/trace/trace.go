@@ -0,0 +1,186 @@
+// Package trace records what Intcode machines spend their time doing.
+//
+// A trace file has a line for every executed instruction:
+//
+//	<machine> <address> <mnemonic> <operands> [<address>=<value>]
+//
+// so the MUL at the start of 1002,4,3,4,33 in the first machine is traced as
+// "0 0 MUL 33,3,4 4=99". A profile counts the instructions executed per opcode
+// and per address and prints the busiest of each.
+//
+// Any Go solution can turn these on from the command line:
+//
+//	$ go run 09/go/part02.go -trace boost.trace -profile
+package trace
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"sync"
+
+	"github.com/dcoxall/advent-of-code-2019/intcode"
+)
+
+// Session traces every machine attached to it into the same file and
+// profile. It is safe to attach machines running in different goroutines.
+type Session struct {
+	path    string
+	profile bool
+
+	mu       sync.Mutex
+	machines int
+	file     *os.File
+	w        *bufio.Writer
+	counts   *Profile
+}
+
+// Flags registers the -trace and -profile flags with the default flag set.
+// It must be called before flag.Parse and the session started after.
+func Flags() *Session {
+	s := &Session{}
+	flag.StringVar(&s.path, "trace", "", "write a trace of every executed Intcode instruction to this file")
+	flag.BoolVar(&s.profile, "profile", false, "print instruction counts per opcode and address when finished")
+	return s
+}
+
+// Start opens the trace file if one is wanted.
+func (s *Session) Start() error {
+	if s.profile {
+		s.counts = NewProfile()
+	}
+	if s.path != "" {
+		f, err := os.Create(s.path)
+		if err != nil {
+			return err
+		}
+		s.file = f
+		s.w = bufio.NewWriter(f)
+	}
+	return nil
+}
+
+// Begin starts the session and returns the function that stops it, so a
+// main function only needs:
+//
+//	flag.Parse()
+//	defer tracing.Begin()()
+//
+// There's no point carrying on if the trace file can't be created so the
+// program exits. Anything going wrong when it is stopped is printed, so a
+// truncated trace isn't mistaken for a complete one.
+func (s *Session) Begin() (stop func()) {
+	if err := s.Start(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	return func() {
+		if err := s.Stop(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+	}
+}
+
+// Attach traces the machine, doing nothing when neither flag was given.
+func (s *Session) Attach(prog *intcode.Intcode) {
+	if s.w == nil && s.counts == nil {
+		return
+	}
+
+	s.mu.Lock()
+	id := s.machines
+	s.machines++
+	s.mu.Unlock()
+
+	prog.SetTracer(&machineTracer{session: s, id: id})
+}
+
+// Stop flushes the trace file and prints the profile to stderr.
+func (s *Session) Stop() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var err error
+	if s.w != nil {
+		err = s.w.Flush()
+		if closeErr := s.file.Close(); err == nil {
+			err = closeErr
+		}
+		s.w = nil
+	}
+	if s.counts != nil {
+		s.counts.Report(os.Stderr, 20)
+		s.counts = nil
+	}
+	return err
+}
+
+type machineTracer struct {
+	session *Session
+	id      int
+	line    []byte
+}
+
+func (t *machineTracer) Trace(ev intcode.Event) {
+	t.session.mu.Lock()
+	defer t.session.mu.Unlock()
+
+	if t.session.counts != nil {
+		t.session.counts.Trace(ev)
+	}
+	if t.session.w != nil {
+		t.line = AppendEvent(t.line[:0], t.id, ev)
+		t.session.w.Write(t.line)
+	}
+}
+
+// AppendEvent appends the trace file line for the event to buf.
+func AppendEvent(buf []byte, machine int, ev intcode.Event) []byte {
+	buf = strconv.AppendInt(buf, int64(machine), 10)
+	buf = append(buf, ' ')
+	buf = strconv.AppendInt(buf, ev.Instruction.Address, 10)
+	buf = append(buf, ' ')
+	buf = append(buf, ev.Instruction.Mnemonic()...)
+	for i, operand := range ev.Operands {
+		if i == 0 {
+			buf = append(buf, ' ')
+		} else {
+			buf = append(buf, ',')
+		}
+		buf = strconv.AppendInt(buf, operand, 10)
+	}
+	for _, write := range ev.Writes {
+		buf = append(buf, ' ')
+		buf = strconv.AppendInt(buf, write.Address, 10)
+		buf = append(buf, '=')
+		buf = strconv.AppendInt(buf, write.Value, 10)
+	}
+	return append(buf, '\n')
+}
+
+// Writer is a Tracer that writes the trace file format to w.
+type Writer struct {
+	w       io.Writer
+	machine int
+	line    []byte
+	err     error
+}
+
+func NewWriter(w io.Writer, machine int) *Writer {
+	return &Writer{w: w, machine: machine}
+}
+
+func (w *Writer) Trace(ev intcode.Event) {
+	if w.err != nil {
+		return
+	}
+	w.line = AppendEvent(w.line[:0], w.machine, ev)
+	_, w.err = w.w.Write(w.line)
+}
+
+func (w *Writer) Err() error {
+	return w.err
+}
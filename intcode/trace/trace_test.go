@@ -0,0 +1,98 @@
+package trace
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/dcoxall/advent-of-code-2019/intcode"
+)
+
+func TestWriter(t *testing.T) {
+	var out strings.Builder
+
+	prog := intcode.New([]int64{1002, 4, 3, 4, 33})
+	prog.SetTracer(NewWriter(&out, 0))
+	prog.Run(context.Background())
+
+	// an input is only traced once it has been read
+	prog = intcode.New([]int64{3, 0, 4, 0, 99})
+	w := NewWriter(&out, 1)
+	prog.SetTracer(w)
+	prog.Run(context.Background())
+	prog.ResumeWith(7)
+
+	expected := "0 0 MUL 33,3,4 4=99\n0 4 HLT\n1 0 IN 0 0=7\n1 2 OUT 7\n1 4 HLT\n"
+	if out.String() != expected {
+		t.Errorf("expected:\n%s\nbut got:\n%s", expected, out.String())
+	}
+	if w.Err() != nil {
+		t.Error(w.Err())
+	}
+}
+
+func TestProfile(t *testing.T) {
+	// counts down from 3
+	prog := intcode.New([]int64{1001, 10, -1, 10, 1005, 10, 0, 99, 0, 0, 3})
+	profile := NewProfile()
+	prog.SetTracer(profile)
+	prog.Run(context.Background())
+
+	if profile.Total != 7 {
+		t.Errorf("expected 7 instructions but got %d", profile.Total)
+	}
+	opcodes := map[int64]int64{intcode.OpAdd: 3, intcode.OpJmpTrue: 3, intcode.OpHalt: 1}
+	if !reflect.DeepEqual(profile.Opcodes, opcodes) {
+		t.Errorf("expected opcodes %v but got %v", opcodes, profile.Opcodes)
+	}
+	addresses := map[int64]int64{0: 3, 4: 3, 7: 1}
+	if !reflect.DeepEqual(profile.Addresses, addresses) {
+		t.Errorf("expected addresses %v but got %v", addresses, profile.Addresses)
+	}
+
+	var out strings.Builder
+	profile.Report(&out, 2)
+	expected := `instructions executed: 7
+
+  opcode  count     %
+     ADD      3  42.9
+      JT      3  42.9
+     HLT      1  14.3
+
+  address  count     %  opcode
+        0      3  42.9     ADD
+        4      3  42.9      JT
+`
+	if out.String() != expected {
+		t.Errorf("expected:\n%s\nbut got:\n%s", expected, out.String())
+	}
+}
+
+func TestSession(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.trace")
+	s := &Session{path: path}
+	if err := s.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		prog := intcode.New([]int64{104, int64(i), 99})
+		s.Attach(prog)
+		prog.Run(context.Background())
+	}
+	if err := s.Stop(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "0 0 OUT 0\n0 2 HLT\n1 0 OUT 1\n1 2 HLT\n"
+	if string(data) != expected {
+		t.Errorf("expected:\n%s\nbut got:\n%s", expected, data)
+	}
+}
@@ -0,0 +1,58 @@
+package intcode
+
+// Tracer is told about every instruction a machine executes. An instruction
+// that has to wait for input isn't reported until it completes.
+type Tracer interface {
+	Trace(ev Event)
+}
+
+// Event describes an executed instruction. Operands holds the value of each
+// parameter as the instruction saw it, which for a parameter that is written
+// to is the address it refers to. Writes lists the memory it changed.
+type Event struct {
+	Instruction Instruction
+	Operands    []int64
+	Writes      []Write
+}
+
+type Write struct {
+	Address int64
+	Value   int64
+}
+
+// SetTracer starts reporting every executed instruction to the tracer. A nil
+// tracer stops tracing.
+func (prog *Intcode) SetTracer(tracer Tracer) {
+	prog.tracer = tracer
+}
+
+// traceEvent resolves the operands of an instruction that is about to be
+// executed.
+func (prog *Intcode) traceEvent(inst Instruction) Event {
+	count := inst.Parameters()
+	ev := Event{Instruction: inst, Operands: make([]int64, count)}
+	target, writing := writes(inst.Opcode)
+
+	for n := int64(0); n < count; n++ {
+		addr := prog.param(inst.Modes, n)
+		if writing && n == target {
+			ev.Operands[n] = addr
+		} else {
+			ev.Operands[n] = prog.memory.Read(addr)
+		}
+	}
+	return ev
+}
+
+// traceComplete adds the memory written by the instruction to the event and
+// passes it on to the tracer.
+func (prog *Intcode) traceComplete(ev Event) {
+	if prog.state == NeedsInput || prog.interrupted != nil {
+		return
+	}
+	if n, ok := writes(ev.Instruction.Opcode); ok {
+		addr := ev.Operands[n]
+		ev.Writes = []Write{{Address: addr, Value: prog.memory.Read(addr)}}
+	}
+	prog.tracer.Trace(ev)
+}
@@ -0,0 +1,139 @@
+// Package ocr reads the block capital letters that some puzzles draw as their
+// answer, such as the registration identifier painted in Day 11 or the image
+// decoded in Day 8.
+//
+// Each letter is 4 points wide and 6 high, with a blank column between
+// letters, so they sit in cells 5 columns apart.
+package ocr
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dcoxall/advent-of-code-2019/grid"
+)
+
+const (
+	width  = 4
+	height = 6
+	stride = width + 1
+)
+
+var glyphs = map[string]rune{}
+
+func init() {
+	letters := map[rune][height]string{
+		'A': {".##.", "#..#", "#..#", "####", "#..#", "#..#"},
+		'B': {"###.", "#..#", "###.", "#..#", "#..#", "###."},
+		'C': {".##.", "#..#", "#...", "#...", "#..#", ".##."},
+		'E': {"####", "#...", "###.", "#...", "#...", "####"},
+		'F': {"####", "#...", "###.", "#...", "#...", "#..."},
+		'G': {".##.", "#..#", "#...", "#.##", "#..#", ".###"},
+		'H': {"#..#", "#..#", "####", "#..#", "#..#", "#..#"},
+		'I': {".###", "..#.", "..#.", "..#.", "..#.", ".###"},
+		'J': {"..##", "...#", "...#", "...#", "#..#", ".##."},
+		'K': {"#..#", "#.#.", "##..", "#.#.", "#.#.", "#..#"},
+		'L': {"#...", "#...", "#...", "#...", "#...", "####"},
+		'O': {".##.", "#..#", "#..#", "#..#", "#..#", ".##."},
+		'P': {"###.", "#..#", "#..#", "###.", "#...", "#..."},
+		'R': {"###.", "#..#", "#..#", "###.", "#.#.", "#..#"},
+		'S': {".###", "#...", "#...", ".##.", "...#", "###."},
+		'U': {"#..#", "#..#", "#..#", "#..#", "#..#", ".##."},
+		'Z': {"####", "...#", "..#.", ".#..", "#...", "####"},
+	}
+	for letter, rows := range letters {
+		glyphs[strings.Join(rows[:], "\n")] = letter
+	}
+}
+
+// UnknownGlyphError is returned when a cell doesn't look like any known
+// letter.
+type UnknownGlyphError struct {
+	// Position is the index of the cell, counting from 0 on the left.
+	Position int
+	// Glyph is the cell drawn with # for lit points and . for the rest.
+	Glyph string
+}
+
+func (e *UnknownGlyphError) Error() string {
+	return fmt.Sprintf("ocr: unknown glyph at position %d:\n%s", e.Position, e.Glyph)
+}
+
+// Read returns the letters drawn by the cells equal to on, reading from the
+// leftmost lit point. A letter whose first column is blank, such as I, can
+// start to the left of that so each possible alignment is tried in turn.
+func Read[T comparable](cells map[grid.Point]T, on T) (string, error) {
+	lit := make(map[grid.Point]bool)
+	var lo, hi grid.Point
+	for p, val := range cells {
+		if val != on {
+			continue
+		}
+		if len(lit) == 0 {
+			lo, hi = p, p
+		}
+		lit[p] = true
+		lo.X, lo.Y = min(lo.X, p.X), min(lo.Y, p.Y)
+		hi.X, hi.Y = max(hi.X, p.X), max(hi.Y, p.Y)
+	}
+	if len(lit) == 0 {
+		return "", fmt.Errorf("ocr: nothing to read")
+	}
+	if hi.Y-lo.Y >= height {
+		return "", fmt.Errorf("ocr: letters are %d high but the drawing is %d", height, hi.Y-lo.Y+1)
+	}
+
+	var first error
+	for offset := 0; offset < width; offset++ {
+		letters, err := read(lit, grid.Point{X: lo.X - offset, Y: lo.Y}, hi.X)
+		if err == nil {
+			return letters, nil
+		}
+		if first == nil {
+			first = err
+		}
+	}
+	return "", first
+}
+
+// read decodes the cells starting at origin until one starts past the last
+// column.
+func read(lit map[grid.Point]bool, origin grid.Point, last int) (string, error) {
+	var sb strings.Builder
+	for pos, x := 0, origin.X; x <= last; pos, x = pos+1, x+stride {
+		glyph := cell(lit, grid.Point{X: x, Y: origin.Y})
+		letter, ok := glyphs[glyph]
+		if !ok || !blank(lit, grid.Point{X: x + width, Y: origin.Y}) {
+			return "", &UnknownGlyphError{Position: pos, Glyph: glyph}
+		}
+		sb.WriteRune(letter)
+	}
+	return sb.String(), nil
+}
+
+// cell draws the glyph with its top left corner at origin.
+func cell(lit map[grid.Point]bool, origin grid.Point) string {
+	rows := make([]string, height)
+	for y := 0; y < height; y++ {
+		var row strings.Builder
+		for x := 0; x < width; x++ {
+			if lit[grid.Point{X: origin.X + x, Y: origin.Y + y}] {
+				row.WriteByte('#')
+			} else {
+				row.WriteByte('.')
+			}
+		}
+		rows[y] = row.String()
+	}
+	return strings.Join(rows, "\n")
+}
+
+// blank reports whether the column of a cell starting at top is unlit.
+func blank(lit map[grid.Point]bool, top grid.Point) bool {
+	for y := 0; y < height; y++ {
+		if lit[grid.Point{X: top.X, Y: top.Y + y}] {
+			return false
+		}
+	}
+	return true
+}
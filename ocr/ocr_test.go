@@ -0,0 +1,94 @@
+package ocr
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/dcoxall/advent-of-code-2019/grid"
+)
+
+// parse turns a drawing into cells with its top left corner at origin. Lit
+// points are true and the rest are false, as a painted canvas would have
+// both colours in it.
+func parse(drawing string, origin grid.Point) map[grid.Point]bool {
+	cells := make(map[grid.Point]bool)
+	for y, row := range strings.Split(strings.TrimSpace(drawing), "\n") {
+		for x, c := range row {
+			cells[grid.Point{X: origin.X + x, Y: origin.Y + y}] = c == '#'
+		}
+	}
+	return cells
+}
+
+// the registration identifier painted in Day 11
+const identifier = `
+.#..#.#.....##..####..##..####..##..#..#...
+.#.#..#....#..#....#.#..#.#....#..#.#..#...
+.##...#....#......#..#..#.###..#....#..#...
+.#.#..#....#.....#...####.#....#.##.#..#...
+.#.#..#....#..#.#....#..#.#....#..#.#..#...
+.#..#.####..##..####.#..#.####..###..##....
+`
+
+func TestRead(t *testing.T) {
+	origins := []grid.Point{{X: 0, Y: 0}, {X: -20, Y: -3}, {X: 7, Y: -100}}
+	for _, origin := range origins {
+		letters, err := Read(parse(identifier, origin), true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if letters != "KLCZAEGU" {
+			t.Errorf("at %v expected KLCZAEGU but got %s", origin, letters)
+		}
+	}
+}
+
+func TestReadAlignment(t *testing.T) {
+	// I has nothing in its first column, so the leftmost lit point isn't
+	// where the cell starts
+	drawing := `
+.###.#..#.###.
+..#..#..#.#..#
+..#..#..#.#..#
+..#..#..#.###.
+..#..#..#.#...
+.###..##..#...
+`
+	letters, err := Read(parse(drawing, grid.Point{X: -3, Y: 2}), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if letters != "IUP" {
+		t.Errorf("expected IUP but got %s", letters)
+	}
+}
+
+func TestReadUnknown(t *testing.T) {
+	drawing := `
+#..#.#...
+#..#.#...
+####.#...
+#..#.##..
+#..#.#.#.
+#..#.#..#
+`
+	_, err := Read(parse(drawing, grid.Point{}), true)
+
+	var unknown *UnknownGlyphError
+	if !errors.As(err, &unknown) {
+		t.Fatalf("expected an unknown glyph but got %v", err)
+	}
+	if unknown.Position != 1 {
+		t.Errorf("expected the unknown glyph at 1 but got %d", unknown.Position)
+	}
+	if expected := "#...\n#...\n#...\n##..\n#.#.\n#..#"; unknown.Glyph != expected {
+		t.Errorf("expected the glyph\n%s\nbut got\n%s", expected, unknown.Glyph)
+	}
+}
+
+func TestReadNothing(t *testing.T) {
+	if _, err := Read(parse("....\n....", grid.Point{}), true); err == nil {
+		t.Error("expected an error with nothing lit")
+	}
+}